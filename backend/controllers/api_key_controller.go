@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAPIKeyLifetimeDays = 90
+	maxAPIKeyLifetimeDays     = 365
+)
+
+// apiKeyResponse renders a key without its secret.
+func apiKeyResponse(key models.APIKey) gin.H {
+	scopes := []string{}
+	if key.Scopes != "" {
+		scopes = strings.Split(key.Scopes, ",")
+	}
+	return gin.H{
+		"id":           key.ID,
+		"name":         key.Name,
+		"prefix":       key.Prefix,
+		"scopes":       scopes,
+		"expires_at":   key.ExpiresAt,
+		"last_used_at": key.LastUsedAt,
+		"revoked_at":   key.RevokedAt,
+		"created_at":   key.CreatedAt,
+	}
+}
+
+// ListAPIKeyScopes returns the scopes that can be granted to an API key.
+func ListAPIKeyScopes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"scopes": utils.APIKeyScopes})
+}
+
+// CreateAPIKey issues an API key for the logged-in user. The key itself is
+// only returned in this response.
+func CreateAPIKey(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name          string   `json:"name" binding:"required"`
+		Scopes        []string `json:"scopes" binding:"required"`
+		ExpiresInDays *int     `json:"expires_in_days"` // Default 90, 0 for a key that never expires
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(input.Scopes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one scope is required"})
+		return
+	}
+	seen := make(map[string]bool)
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !utils.IsAPIKeyScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	days := defaultAPIKeyLifetimeDays
+	if input.ExpiresInDays != nil {
+		days = *input.ExpiresInDays
+	}
+	if days < 0 || days > maxAPIKeyLifetimeDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be between 0 and 365"})
+		return
+	}
+	var expiresAt *time.Time
+	if days > 0 {
+		t := time.Now().AddDate(0, 0, days)
+		expiresAt = &t
+	}
+
+	raw, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	key := models.APIKey{
+		ID:        uuid.New(),
+		UserID:    uuid.MustParse(userClaims.UserID),
+		Name:      input.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+	if err := utils.DB.Create(&key).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created successfully. Store it now, it will not be shown again",
+		"key":     raw,
+		"api_key": apiKeyResponse(key),
+	})
+}
+
+// ListAPIKeys lists the logged-in user's API keys, including revoked ones.
+func ListAPIKeys(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var keys []models.APIKey
+	if err := utils.DB.Scopes(apiKeyPolicy(userClaims)).Order("created_at desc").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, apiKeyResponse(key))
+	}
+	c.JSON(http.StatusOK, gin.H{"api_keys": response})
+}
+
+// RevokeAPIKey stops an API key from working. Revoked keys stay listed.
+func RevokeAPIKey(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var key models.APIKey
+	if err := utils.DB.Scopes(apiKeyPolicy(userClaims)).Where("id = ?", c.Param("id")).First(&key).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now()
+		if err := utils.DB.Model(&key).Update("revoked_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+			return
+		}
+		key.RevokedAt = &now
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully", "api_key": apiKeyResponse(key)})
+}
@@ -1,16 +1,21 @@
 package controllers
 
 import (
+	"backend/filter"
 	"backend/models"
+	"backend/slug"
 	"backend/utils"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 const campaignCreatedEmailTemplate = `<!DOCTYPE html>
@@ -86,12 +91,6 @@ func CreateCampaign(c *gin.Context) {
 		return
 	}
 
-	// Only admin or valid user can create campaigns
-	if userClaims.Role != "admin" && userClaims.Role != "campaign_creator" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
-		return
-	}
-
 	// Bind input JSON
 	var input struct {
 		Title        string    `json:"title" binding:"required"`
@@ -100,12 +99,18 @@ func CreateCampaign(c *gin.Context) {
 		Deadline     time.Time `json:"deadline" binding:"required"`
 		Currency     string    `json:"currency" binding:"required"`
 		Category     string    `json:"category" binding:"required"`
+		Submit       bool      `json:"submit"` // Submit for review right away instead of saving a draft
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	status := models.CampaignStatusDraft
+	if input.Submit {
+		status = models.CampaignStatusPendingReview
+	}
+
 	// Create the campaign
 	campaign := models.Campaign{
 		CreatorID:    uuid.MustParse(userClaims.UserID),
@@ -115,11 +120,31 @@ func CreateCampaign(c *gin.Context) {
 		Deadline:     input.Deadline,
 		Currency:     input.Currency,
 		Category:     input.Category,
-		Status:       "pending", // Default status
+		Status:       status,
+	}
+
+	// Save to database with an unused slug made from the title, and the first
+	// revision of its history. Another campaign may take the slug between
+	// picking and inserting it, in which case the next free one is picked.
+	base := slug.Make(input.Title)
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		var campaignSlug string
+		if campaignSlug, err = uniqueCampaignSlug(utils.DB, base); err != nil {
+			break
+		}
+		campaign.Slug = &campaignSlug
+		err = utils.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&campaign).Error; err != nil {
+				return err
+			}
+			return recordCampaignRevision(tx, campaign.ID, campaign.CreatorID, campaignChanges(nil, campaign), nil)
+		})
+		if !slugConflict(err) {
+			break
+		}
 	}
-
-	// Save to database
-	if err := utils.DB.Create(&campaign).Error; err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create campaign"})
 		return
 	}
@@ -138,7 +163,7 @@ func CreateCampaign(c *gin.Context) {
 
 	// 2) fire off email using user.FullName
 	go func(to, name string, cam models.Campaign) {
-		subject := "Your new campaign is live on Impacta!"
+		subject := "Your new campaign has been created on Impacta"
 		body := campaignCreatedEmailTemplate
 		replacements := map[string]string{
 			"{{.CreatorName}}":  name,
@@ -181,8 +206,9 @@ func UpdateCampaign(c *gin.Context) {
 		return
 	}
 
-	// Check permissions: Admins can update any campaign, others can update only their own
-	if userClaims.Role != "admin" && campaign.CreatorID.String() != userClaims.UserID {
+	// Check permissions: moderators can update any campaign, others need an
+	// editing role on its team
+	if !campaignAllows(userClaims, campaign, models.CampaignCanEdit) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
 		return
 	}
@@ -195,37 +221,90 @@ func UpdateCampaign(c *gin.Context) {
 		Deadline     time.Time `json:"deadline,omitempty"`
 		Status       string    `json:"status,omitempty"`
 		Category     string    `json:"category,omitempty"`
+		Slug         string    `json:"slug,omitempty"` // The old slug keeps redirecting here
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if input.Slug != "" && !slug.Valid(input.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Slug must be %d to %d lowercase letters, digits and single hyphens", slug.MinLength, slug.MaxLength)})
+		return
+	}
+	before := campaign
 
-	// Update fields
+	// Update fields, noting their columns: only those are written back, so
+	// the amount raised and a status set meanwhile are left alone
+	var columns []string
 	if input.Title != "" {
 		campaign.Title = input.Title
+		columns = append(columns, "title")
 	}
 	if input.Description != "" {
 		campaign.Description = input.Description
+		columns = append(columns, "description")
 	}
 	if input.TargetAmount != 0 {
 		campaign.TargetAmount = input.TargetAmount
+		columns = append(columns, "target_amount")
 	}
 	if !input.Deadline.IsZero() {
 		campaign.Deadline = input.Deadline
+		columns = append(columns, "deadline")
 	}
-	if input.Status != "" {
+	if input.Status != "" && input.Status != campaign.Status {
+		if !campaignAllows(userClaims, campaign, models.CampaignCanManage) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the campaign's owners can change its status"})
+			return
+		}
+		if !models.CanTransitionCampaign(campaign.Status, input.Status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change campaign status from " + campaign.Status + " to " + input.Status})
+			return
+		}
+		// Approval and rejection go through the review endpoints
+		if campaign.Status == models.CampaignStatusPendingReview &&
+			(input.Status == models.CampaignStatusActive || input.Status == models.CampaignStatusRejected) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Campaigns are approved or rejected by a reviewer"})
+			return
+		}
+		// A pause may be a moderation action, so only moderators lift it
+		if campaign.Status == models.CampaignStatusPaused && input.Status == models.CampaignStatusActive &&
+			!utils.HasPermission(userClaims.Role, utils.PermCampaignModerate) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Paused campaigns are resumed by a moderator"})
+			return
+		}
 		campaign.Status = input.Status
+		columns = append(columns, "status")
 	}
 	if input.Category != "" {
 		campaign.Category = input.Category
+		columns = append(columns, "category")
 	}
 
-	// Save to database
-	if err := utils.DB.Save(&campaign).Error; err != nil {
+	// Save to database, keeping the edit in the campaign's revisions
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Slug != "" && (campaign.Slug == nil || input.Slug != *campaign.Slug) {
+			if err := changeCampaignSlug(tx, &campaign, input.Slug); err != nil {
+				return err
+			}
+			columns = append(columns, "slug")
+		}
+		if len(columns) > 0 {
+			if err := tx.Model(&campaign).Select(columns).Updates(&campaign).Error; err != nil {
+				return err
+			}
+		}
+		return recordCampaignRevision(tx, campaign.ID, uuid.MustParse(userClaims.UserID), campaignChanges(&before, campaign), nil)
+	})
+	if errors.Is(err, errSlugTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug is already taken"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update campaign"})
 		return
 	}
+	recordAuditChange(c, "campaign", campaign.ID.String(), before, campaign)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Campaign updated successfully", "campaign": campaign})
 }
@@ -253,8 +332,8 @@ func DeleteCampaign(c *gin.Context) {
 		return
 	}
 
-	// Check permissions: Admins can delete any campaign, others can delete only their own
-	if userClaims.Role != "admin" && campaign.CreatorID.String() != userClaims.UserID {
+	// Check permissions: moderators can delete any campaign, others must own it
+	if !campaignAllows(userClaims, campaign, models.CampaignCanManage) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
 		return
 	}
@@ -264,6 +343,7 @@ func DeleteCampaign(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete campaign"})
 		return
 	}
+	recordAuditChange(c, "campaign", campaign.ID.String(), campaign, nil)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Campaign deleted successfully"})
 }
@@ -275,11 +355,12 @@ func ListCampaigns(c *gin.Context) {
 	status := c.Query("status")
 	minTargetAmount := c.Query("min_target_amount")
 	maxTargetAmount := c.Query("max_target_amount")
-	sortBy := c.Query("sort_by") // e.g., "created_at" or "target_amount"
-	order := c.Query("order")    // e.g., "asc" or "desc"
 
 	// Initialize the query
-	query := utils.DB.Model(&models.Campaign{})
+	query, ok := applyFilter(c, visibleCampaigns(c, utils.DB.Model(&models.Campaign{})), campaignFields)
+	if !ok {
+		return
+	}
 
 	// Apply filters dynamically
 	if title != "" {
@@ -299,31 +380,61 @@ func ListCampaigns(c *gin.Context) {
 	}
 
 	// Apply sorting
-	if sortBy != "" {
-		// Default to ascending order if order is not specified or invalid
-		if order != "asc" && order != "desc" {
-			order = "asc"
-		}
-		query = query.Order(sortBy + " " + order)
+	sort, ok := listSort(c, campaignFields)
+	if !ok {
+		return
+	}
+	page, ok := newPage(c, "campaigns", sort...)
+	if !ok {
+		return
 	}
 
 	// Fetch campaigns
 	var campaigns []models.Campaign
-	if err := query.Find(&campaigns).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch campaigns"})
+	result, ok := findPage(c, page, query, &campaigns, "Failed to fetch campaigns")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"campaigns": campaigns})
+	c.JSON(http.StatusOK, result.Apply(gin.H{"campaigns": campaigns}))
+}
+
+// campaignFields are the fields ListCampaigns can filter and sort by.
+var campaignFields = filter.Fields{
+	"title":          {Column: "title", Kind: filter.String, Sortable: true},
+	"category":       {Column: "category", Kind: filter.String},
+	"status":         {Column: "status", Kind: filter.String},
+	"currency":       {Column: "currency", Kind: filter.String},
+	"creator_id":     {Column: "creator_id", Kind: filter.UUID},
+	"target_amount":  {Column: "target_amount", Kind: filter.Number, Sortable: true},
+	"current_amount": {Column: "current_amount", Kind: filter.Number, Sortable: true},
+	"deadline":       {Column: "deadline", Kind: filter.Time, Sortable: true},
+	"created_at":     {Column: "created_at", Kind: filter.Time, Sortable: true},
+}
+
+// visibleCampaigns limits a campaign query to what the caller may list. The
+// public only sees live campaigns. Reviewers see every campaign and creators
+// also see their own drafts and submissions.
+func visibleCampaigns(c *gin.Context, query *gorm.DB) *gorm.DB {
+	claims, _ := c.Get("claims")
+	userClaims, _ := claims.(*utils.Claims)
+	switch {
+	case userClaims != nil && utils.HasPermission(userClaims.Role, utils.PermCampaignReview):
+		return query
+	case userClaims != nil:
+		return query.Where("(status = ? OR id IN (?))", models.CampaignStatusActive, campaignsWith(userClaims.UserID, models.CampaignCanView))
+	default:
+		return query.Where("status = ?", models.CampaignStatusActive)
+	}
 }
 
 func GetCampaign(c *gin.Context) {
-	// Retrieve the campaign ID from the route parameters
+	// The route parameter is either the campaign ID or a slug
 	id := c.Param("id")
 
 	// Fetch the campaign from the database
-	var campaign models.Campaign
-	if err := utils.DB.Where("id = ?", id).First(&campaign).Error; err != nil {
+	campaign, moved, err := findCampaign(c, id)
+	if err != nil {
 		if err.Error() == "record not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
 		} else {
@@ -332,6 +443,18 @@ func GetCampaign(c *gin.Context) {
 		return
 	}
 
+	// Old slugs point at the campaign's current one
+	if moved != "" {
+		c.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(c.Request.URL.Path), moved))
+		return
+	}
+
+	milestones, err := campaignMilestones(campaign.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch milestones"})
+		return
+	}
+
 	// Return the campaign details
-	c.JSON(http.StatusOK, gin.H{"campaign": campaign})
+	c.JSON(http.StatusOK, gin.H{"campaign": campaign, "milestones": milestones})
 }
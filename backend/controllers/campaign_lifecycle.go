@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm/clause"
+)
+
+// RunCampaignLifecycle ends campaigns that are done: active campaigns that
+// reached their target are completed, and campaigns past their deadline are
+// completed if funded and closed otherwise. The creator and the donors of each
+// campaign it ends get a summary. The job scheduler runs it periodically.
+func RunCampaignLifecycle(ctx context.Context) error {
+	db := utils.DB.WithContext(ctx)
+
+	var funded []models.Campaign
+	if err := db.Model(&funded).Clauses(clause.Returning{}).
+		Where("status IN ? AND current_amount >= target_amount", []string{models.CampaignStatusActive, models.CampaignStatusPaused}).
+		Where("(status = ? OR deadline < ?)", models.CampaignStatusActive, time.Now().UTC()).
+		Update("status", models.CampaignStatusCompleted).Error; err != nil {
+		return err
+	}
+
+	var expired []models.Campaign
+	if err := db.Model(&expired).Clauses(clause.Returning{}).
+		Where("status IN ? AND deadline < ?", []string{models.CampaignStatusActive, models.CampaignStatusPaused}, time.Now().UTC()).
+		Update("status", models.CampaignStatusClosed).Error; err != nil {
+		return err
+	}
+
+	for _, campaign := range append(funded, expired...) {
+		if err := sendCampaignSummary(campaign); err != nil {
+			log.Printf("Failed to send summary for campaign %s: %v", campaign.ID, err)
+		}
+	}
+	if len(funded) > 0 || len(expired) > 0 {
+		log.Printf("campaign lifecycle: %d completed, %d closed", len(funded), len(expired))
+	}
+	return nil
+}
+
+// sendCampaignSummary tells the creator and every donor how an ended campaign
+// did.
+func sendCampaignSummary(campaign models.Campaign) error {
+	var totals struct {
+		Donations int64
+		Raised    float64
+	}
+	if err := utils.DB.Model(&models.Donation{}).
+		Select("COUNT(*) AS donations, COALESCE(SUM(amount), 0) AS raised").
+		Where("campaign_id = ? AND status = ?", campaign.ID, "completed").
+		Scan(&totals).Error; err != nil {
+		return err
+	}
+
+	outcome := "has ended"
+	if campaign.Status == models.CampaignStatusCompleted {
+		outcome = "has reached its goal"
+	}
+	summary := fmt.Sprintf("The campaign \"%s\" %s. It raised %.2f of its %.2f %s target from %d donations.",
+		campaign.Title, outcome, totals.Raised, campaign.TargetAmount, campaign.Currency, totals.Donations)
+	subject := "Campaign summary: " + campaign.Title
+	link := utils.FrontendURL("/campaigns/" + campaign.ID.String())
+
+	notifyUser(campaign.CreatorID, "campaign_summary", summary)
+	var creator models.User
+	if err := utils.DB.Select("email", "full_name", "status").Where("id = ?", campaign.CreatorID).First(&creator).Error; err != nil {
+		return err
+	}
+	if creator.Status != models.UserStatusErased {
+		sendActionEmail(creator.Email, creator.FullName, subject, summary, "View your campaign", link)
+	}
+
+	// Erased donors keep their donations but must not be contacted
+	var donors []models.User
+	if err := utils.DB.Distinct("users.email", "users.full_name").
+		Joins("JOIN donations ON donations.donor_id = users.id").
+		Where("donations.campaign_id = ? AND donations.status = ? AND users.status <> ?", campaign.ID, "completed", models.UserStatusErased).
+		Find(&donors).Error; err != nil {
+		return err
+	}
+	for _, donor := range donors {
+		sendActionEmail(donor.Email, donor.FullName, subject, "Thank you for your support. "+summary, "View the campaign", link)
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const campaignInvitationTTL = 7 * 24 * time.Hour
+
+var (
+	errInvalidInvitation = errors.New("invalid or expired invitation")
+	errInvitationEmail   = errors.New("invitation sent to another email address")
+)
+
+// campaignMemberResponse is a member of a campaign's team as listed to the
+// rest of the team.
+type campaignMemberResponse struct {
+	UserID   uuid.UUID `json:"user_id"`
+	FullName string    `json:"full_name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// ListCampaignMembers lists a campaign's team, creator first. Owners also
+// see the invitations not accepted yet.
+func ListCampaignMembers(c *gin.Context) {
+	userClaims, campaign, ok := campaignForTeam(c, models.CampaignCanView)
+	if !ok {
+		return
+	}
+
+	var creator models.User
+	if err := utils.DB.Select("id", "full_name", "email").Where("id = ?", campaign.CreatorID).First(&creator).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team"})
+		return
+	}
+	members := []campaignMemberResponse{{
+		UserID: creator.ID, FullName: creator.FullName, Email: creator.Email,
+		Role: models.CampaignRoleOwner, JoinedAt: campaign.CreatedAt,
+	}}
+	var team []campaignMemberResponse
+	if err := utils.DB.Table("campaignmembers").
+		Select("campaignmembers.user_id, users.full_name, users.email, campaignmembers.role, campaignmembers.created_at AS joined_at").
+		Joins("JOIN users ON users.id = campaignmembers.user_id").
+		Where("campaignmembers.campaign_id = ? AND campaignmembers.user_id <> ?", campaign.ID, campaign.CreatorID).
+		Order("campaignmembers.created_at").
+		Scan(&team).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team"})
+		return
+	}
+	members = append(members, team...)
+
+	response := gin.H{"members": members}
+	if campaignAllows(userClaims, campaign, models.CampaignCanManage) {
+		invitations := []models.CampaignInvitation{}
+		if err := utils.DB.Where("campaign_id = ? AND accepted_at IS NULL AND expires_at > ?", campaign.ID, time.Now()).
+			Order("created_at").Find(&invitations).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+			return
+		}
+		response["invitations"] = invitations
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// InviteCampaignMember emails someone a link to join the campaign's team
+// with the given role. Inviting the same address again replaces the earlier
+// invitation. Only owners can invite.
+func InviteCampaignMember(c *gin.Context) {
+	userClaims, campaign, ok := campaignForTeam(c, models.CampaignCanManage)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+		Role  string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.ValidCampaignRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be one of owner, editor, finance or viewer"})
+		return
+	}
+	email := strings.TrimSpace(input.Email)
+
+	// Someone already on the team has their role changed with PUT instead
+	var invitee models.User
+	err := utils.DB.Where("LOWER(email) = LOWER(?)", email).First(&invitee).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite member"})
+		return
+	}
+	if err == nil {
+		if campaignRole(campaign, invitee.ID.String()) != "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "This user is already on the campaign's team"})
+			return
+		}
+	}
+
+	rawToken, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation"})
+		return
+	}
+	invitation := models.CampaignInvitation{
+		ID:         uuid.New(),
+		CampaignID: campaign.ID,
+		Email:      email,
+		Role:       input.Role,
+		TokenHash:  tokenHash,
+		InvitedBy:  uuid.MustParse(userClaims.UserID),
+		ExpiresAt:  time.Now().Add(campaignInvitationTTL),
+	}
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("campaign_id = ? AND LOWER(email) = LOWER(?) AND accepted_at IS NULL", campaign.ID, email).
+			Delete(&models.CampaignInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&invitation).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite member"})
+		return
+	}
+	recordAuditChange(c, "campaign_invitation", invitation.ID.String(), nil, gin.H{"email": email, "role": input.Role})
+
+	name := email
+	if invitee.ID != uuid.Nil {
+		name = invitee.FullName
+		notifyUser(invitee.ID, "campaign_invitation",
+			fmt.Sprintf("You have been invited to join the team of \"%s\" as %s. Check your email to accept.", campaign.Title, input.Role))
+	}
+	sendActionEmailHTML(email, name,
+		"You're invited to help run a campaign on Impacta",
+		template.HTML(fmt.Sprintf("You have been invited to join the team of <strong>%s</strong> as %s. The invitation expires in 7 days.",
+			html.EscapeString(campaign.Title), input.Role)),
+		"Accept Invitation",
+		utils.FrontendURL("/campaign-invitations/accept?token="+rawToken))
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Invitation sent", "invitation": invitation})
+}
+
+// RevokeCampaignInvitation cancels an invitation not accepted yet.
+func RevokeCampaignInvitation(c *gin.Context) {
+	_, campaign, ok := campaignForTeam(c, models.CampaignCanManage)
+	if !ok {
+		return
+	}
+	var revoked []models.CampaignInvitation
+	result := utils.DB.Clauses(clause.Returning{}).
+		Where("id = ? AND campaign_id = ? AND accepted_at IS NULL", c.Param("invitation_id"), campaign.ID).
+		Delete(&revoked)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+	if len(revoked) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+	recordAuditChange(c, "campaign_invitation", revoked[0].ID.String(), revoked[0], nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// AcceptCampaignInvitation redeems an invitation link for the logged-in
+// user, whose email must be the one invited.
+func AcceptCampaignInvitation(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", userClaims.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var invitation models.CampaignInvitation
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", utils.HashToken(input.Token), now).
+			First(&invitation).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errInvalidInvitation
+			}
+			return err
+		}
+		if !strings.EqualFold(invitation.Email, user.Email) {
+			return errInvitationEmail
+		}
+		// Claim the invitation atomically so it cannot be redeemed twice
+		result := tx.Model(&invitation).Where("accepted_at IS NULL").Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvalidInvitation
+		}
+
+		var campaign models.Campaign
+		if err := tx.Select("id", "creator_id").Where("id = ?", invitation.CampaignID).First(&campaign).Error; err != nil {
+			return err
+		}
+		if campaign.CreatorID == user.ID {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "campaign_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "invited_by", "updated_at"}),
+		}).Create(&models.CampaignMember{
+			CampaignID: invitation.CampaignID,
+			UserID:     user.ID,
+			Role:       invitation.Role,
+			InvitedBy:  &invitation.InvitedBy,
+		}).Error
+	})
+	switch {
+	case errors.Is(err, errInvalidInvitation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invitation"})
+		return
+	case errors.Is(err, errInvitationEmail):
+		c.JSON(http.StatusForbidden, gin.H{"error": "This invitation was sent to another email address"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Invitation accepted",
+		"campaign_id": invitation.CampaignID,
+		"role":        invitation.Role,
+	})
+}
+
+// UpdateCampaignMember changes a team member's role. The creator always
+// stays an owner.
+func UpdateCampaignMember(c *gin.Context) {
+	_, campaign, ok := campaignForTeam(c, models.CampaignCanManage)
+	if !ok {
+		return
+	}
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.ValidCampaignRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be one of owner, editor, finance or viewer"})
+		return
+	}
+	if c.Param("user_id") == campaign.CreatorID.String() {
+		c.JSON(http.StatusConflict, gin.H{"error": "The campaign's creator is always an owner"})
+		return
+	}
+
+	var member models.CampaignMember
+	if err := utils.DB.Where("campaign_id = ? AND user_id = ?", campaign.ID, c.Param("user_id")).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch member"})
+		}
+		return
+	}
+	before := member
+	if err := utils.DB.Model(&member).Update("role", input.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+	recordAuditChange(c, "campaign_member", member.UserID.String(), before, member)
+	c.JSON(http.StatusOK, gin.H{"message": "Member updated", "user_id": c.Param("user_id"), "role": input.Role})
+}
+
+// RemoveCampaignMember takes someone off the team. Owners can remove anyone
+// but the creator, and members can remove themselves.
+func RemoveCampaignMember(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+	var campaign models.Campaign
+	if err := utils.DB.Where("id = ?", c.Param("campaign_id")).First(&campaign).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+	memberID := c.Param("user_id")
+	if memberID != userClaims.UserID && !campaignAllows(userClaims, campaign, models.CampaignCanManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+	if memberID == campaign.CreatorID.String() {
+		c.JSON(http.StatusConflict, gin.H{"error": "The campaign's creator cannot be removed"})
+		return
+	}
+
+	var removed []models.CampaignMember
+	result := utils.DB.Clauses(clause.Returning{}).Where("campaign_id = ? AND user_id = ?", campaign.ID, memberID).Delete(&removed)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+	if len(removed) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	recordAuditChange(c, "campaign_member", memberID, removed[0], nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// campaignForTeam loads the campaign named in the route and checks that the
+// caller's team role, or a moderator permission, allows capability.
+func campaignForTeam(c *gin.Context, capability string) (*utils.Claims, models.Campaign, bool) {
+	var campaign models.Campaign
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return nil, campaign, false
+	}
+	if err := utils.DB.Where("id = ?", c.Param("campaign_id")).First(&campaign).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return nil, campaign, false
+	}
+	if !campaignAllows(userClaims, campaign, capability) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return nil, campaign, false
+	}
+	return userClaims, campaign, true
+}
+
+// campaignAllows reports whether the caller can act on the campaign: through
+// their team role, or as a moderator, who can act on every campaign.
+func campaignAllows(claims *utils.Claims, campaign models.Campaign, capability string) bool {
+	if utils.HasPermission(claims.Role, utils.PermCampaignModerate) {
+		return true
+	}
+	return models.CampaignRoleCan(campaignRole(campaign, claims.UserID), capability)
+}
+
+// campaignRole returns the user's role on the campaign's team, or "" when
+// they are not on it. The creator is always an owner.
+func campaignRole(campaign models.Campaign, userID string) string {
+	if campaign.CreatorID.String() == userID {
+		return models.CampaignRoleOwner
+	}
+	var member models.CampaignMember
+	err := utils.DB.Select("role").Where("campaign_id = ? AND user_id = ?", campaign.ID, userID).First(&member).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Failed to look up team role on campaign %s: %v", campaign.ID, err)
+		}
+		return ""
+	}
+	return member.Role
+}
+
+// campaignsWith is a subquery of the IDs of the campaigns where the user's
+// team role allows capability, including those they created.
+func campaignsWith(userID, capability string) *gorm.DB {
+	return utils.DB.Model(&models.Campaign{}).Select("id").
+		Where("creator_id = ? OR id IN (?)", userID, utils.DB.Model(&models.CampaignMember{}).
+			Select("campaign_id").Where("user_id = ? AND role IN ?", userID, models.CampaignRolesWith(capability)))
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// ListCampaignMilestones lists a campaign's milestones from the smallest
+// amount up.
+func ListCampaignMilestones(c *gin.Context) {
+	campaign, ok := visibleCampaign(c)
+	if !ok {
+		return
+	}
+	milestones, err := campaignMilestones(campaign.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch milestones"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"milestones": milestones})
+}
+
+// CreateCampaignMilestone adds a milestone to a campaign. A milestone the
+// campaign has already raised is marked reached straight away, without
+// notifications. Only the creator or a moderator can add milestones.
+func CreateCampaignMilestone(c *gin.Context) {
+	_, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Amount      float64 `json:"amount" binding:"required,gt=0"`
+		Title       string  `json:"title" binding:"required"`
+		Description string  `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required"})
+		return
+	}
+	if !milestoneAmountFree(c, campaign.ID, input.Amount, uuid.Nil) {
+		return
+	}
+
+	milestone := models.CampaignMilestone{
+		ID:          uuid.New(),
+		CampaignID:  campaign.ID,
+		Amount:      input.Amount,
+		Title:       input.Title,
+		Description: input.Description,
+	}
+	if input.Amount <= campaign.CurrentAmount {
+		now := time.Now()
+		milestone.ReachedAt = &now
+	}
+	if err := utils.DB.Create(&milestone).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create milestone"})
+		return
+	}
+	recordAuditChange(c, "campaign_milestone", milestone.ID.String(), nil, milestone)
+	c.JSON(http.StatusCreated, gin.H{"message": "Milestone created", "milestone": milestone})
+}
+
+// UpdateCampaignMilestone edits a milestone. The amount of a reached
+// milestone cannot change.
+func UpdateCampaignMilestone(c *gin.Context) {
+	_, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+	milestone, ok := campaignMilestone(c, campaign)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Amount      *float64 `json:"amount"`
+		Title       string   `json:"title"`
+		Description *string  `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Amount != nil && *input.Amount != milestone.Amount {
+		if milestone.ReachedAt != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "The amount of a reached milestone cannot change"})
+			return
+		}
+		if *input.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be greater than 0"})
+			return
+		}
+		if !milestoneAmountFree(c, campaign.ID, *input.Amount, milestone.ID) {
+			return
+		}
+		milestone.Amount = *input.Amount
+		if milestone.Amount <= campaign.CurrentAmount {
+			now := time.Now()
+			milestone.ReachedAt = &now
+		}
+	}
+	if title := strings.TrimSpace(input.Title); title != "" {
+		milestone.Title = title
+	}
+	if input.Description != nil {
+		milestone.Description = *input.Description
+	}
+
+	if err := utils.DB.Save(&milestone).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update milestone"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Milestone updated", "milestone": milestone})
+}
+
+// DeleteCampaignMilestone removes a milestone.
+func DeleteCampaignMilestone(c *gin.Context) {
+	_, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+	milestone, ok := campaignMilestone(c, campaign)
+	if !ok {
+		return
+	}
+	if err := utils.DB.Delete(&milestone).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete milestone"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Milestone deleted"})
+}
+
+// FollowCampaign subscribes the logged-in user to a campaign's milestone
+// notifications.
+func FollowCampaign(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+	campaign, ok := visibleCampaign(c)
+	if !ok {
+		return
+	}
+	if err := followCampaign(campaign.ID, uuid.MustParse(userClaims.UserID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow campaign"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign followed"})
+}
+
+// UnfollowCampaign stops the logged-in user's milestone notifications for a
+// campaign.
+func UnfollowCampaign(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+	if err := utils.DB.Where("campaign_id = ? AND user_id = ?", c.Param("campaign_id"), userClaims.UserID).
+		Delete(&models.CampaignFollower{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow campaign"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign unfollowed"})
+}
+
+// followCampaign makes the user follow the campaign, if they do not already.
+func followCampaign(campaignID, userID uuid.UUID) error {
+	return utils.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.CampaignFollower{CampaignID: campaignID, UserID: userID}).Error
+}
+
+// reachMilestones marks the campaign's milestones up to its current amount as
+// reached and notifies the creator and followers of each. The update only
+// picks milestones not reached yet, so concurrent donations crossing the
+// same milestone notify once.
+func reachMilestones(campaign models.Campaign) error {
+	var reached []models.CampaignMilestone
+	if err := utils.DB.Model(&reached).Clauses(clause.Returning{}).
+		Where("campaign_id = ? AND reached_at IS NULL AND amount <= ?", campaign.ID, campaign.CurrentAmount).
+		Update("reached_at", time.Now()).Error; err != nil {
+		return err
+	}
+	if len(reached) == 0 {
+		return nil
+	}
+
+	var followerIDs []uuid.UUID
+	if err := utils.DB.Model(&models.CampaignFollower{}).
+		Where("campaign_id = ? AND user_id <> ?", campaign.ID, campaign.CreatorID).
+		Pluck("user_id", &followerIDs).Error; err != nil {
+		return err
+	}
+	recipients := append([]uuid.UUID{campaign.CreatorID}, followerIDs...)
+
+	for _, milestone := range reached {
+		content := fmt.Sprintf("\"%s\" reached its milestone \"%s\" (%.2f %s).",
+			campaign.Title, milestone.Title, milestone.Amount, campaign.Currency)
+		if err := notifyUsers(recipients, "campaign_milestone", content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// campaignMilestones returns the campaign's milestones from the smallest
+// amount up.
+func campaignMilestones(campaignID uuid.UUID) ([]models.CampaignMilestone, error) {
+	milestones := []models.CampaignMilestone{}
+	err := utils.DB.Where("campaign_id = ?", campaignID).Order("amount").Find(&milestones).Error
+	return milestones, err
+}
+
+// campaignMilestone loads the milestone named in the route.
+func campaignMilestone(c *gin.Context, campaign models.Campaign) (models.CampaignMilestone, bool) {
+	var milestone models.CampaignMilestone
+	if err := utils.DB.Where("id = ? AND campaign_id = ?", c.Param("milestone_id"), campaign.ID).First(&milestone).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Milestone not found"})
+		return milestone, false
+	}
+	return milestone, true
+}
+
+// milestoneAmountFree checks that no other milestone of the campaign has the
+// same amount.
+func milestoneAmountFree(c *gin.Context, campaignID uuid.UUID, amount float64, except uuid.UUID) bool {
+	var count int64
+	if err := utils.DB.Model(&models.CampaignMilestone{}).
+		Where("campaign_id = ? AND amount = ? AND id <> ?", campaignID, amount, except).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check milestones"})
+		return false
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "The campaign already has a milestone for this amount"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SubmitCampaign sends a draft or rejected campaign to the review queue. Only
+// its owners or a moderator can submit it.
+func SubmitCampaign(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var campaign models.Campaign
+	if err := utils.DB.Where("id = ?", c.Param("id")).First(&campaign).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+	if !campaignAllows(userClaims, campaign, models.CampaignCanManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	if !transitionCampaign(c, &campaign, models.CampaignStatusPendingReview, nil) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign submitted for review", "campaign": campaign})
+}
+
+// ApproveCampaign makes a campaign under review live and records who
+// approved it.
+func ApproveCampaign(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	campaign, ok := campaignUnderReview(c)
+	if !ok {
+		return
+	}
+	before := campaign
+
+	reviewerID := uuid.MustParse(userClaims.UserID)
+	now := time.Now()
+	if !transitionCampaign(c, &campaign, models.CampaignStatusActive, map[string]interface{}{
+		"approved_by":      reviewerID,
+		"approved_at":      now,
+		"rejected_by":      nil,
+		"rejected_at":      nil,
+		"rejection_reason": "",
+	}) {
+		return
+	}
+	recordAuditChange(c, "campaign", campaign.ID.String(), before, campaign)
+
+	notifyCampaignReview(campaign, "Your campaign \""+campaign.Title+"\" has been approved and is now live.")
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign approved successfully", "campaign": campaign})
+}
+
+// RejectCampaign sends a campaign under review back to its creator with a
+// reason. The creator can edit it and submit it again.
+func RejectCampaign(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+
+	campaign, ok := campaignUnderReview(c)
+	if !ok {
+		return
+	}
+	before := campaign
+
+	reviewerID := uuid.MustParse(userClaims.UserID)
+	now := time.Now()
+	if !transitionCampaign(c, &campaign, models.CampaignStatusRejected, map[string]interface{}{
+		"rejected_by":      reviewerID,
+		"rejected_at":      now,
+		"rejection_reason": input.Reason,
+	}) {
+		return
+	}
+	recordAuditChange(c, "campaign", campaign.ID.String(), before, campaign)
+
+	notifyCampaignReview(campaign, "Your campaign \""+campaign.Title+"\" was not approved: "+input.Reason)
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign rejected", "campaign": campaign})
+}
+
+// campaignUnderReview loads the campaign named in the route and checks that
+// it is waiting for review.
+func campaignUnderReview(c *gin.Context) (models.Campaign, bool) {
+	var campaign models.Campaign
+	if err := utils.DB.Where("id = ?", c.Param("id")).First(&campaign).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch campaign"})
+		}
+		return campaign, false
+	}
+	if campaign.Status != models.CampaignStatusPendingReview {
+		c.JSON(http.StatusConflict, gin.H{"error": "Campaign is not pending review"})
+		return campaign, false
+	}
+	return campaign, true
+}
+
+// transitionCampaign moves the campaign to a new status along with any other
+// updates. The update only applies if nobody changed the status meanwhile.
+func transitionCampaign(c *gin.Context, campaign *models.Campaign, status string, updates map[string]interface{}) bool {
+	if !models.CanTransitionCampaign(campaign.Status, status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot change campaign status from " + campaign.Status + " to " + status})
+		return false
+	}
+
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["status"] = status
+	result := utils.DB.Model(&models.Campaign{}).
+		Where("id = ? AND status = ?", campaign.ID, campaign.Status).
+		Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update campaign"})
+		return false
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Campaign status changed, please retry"})
+		return false
+	}
+
+	if err := utils.DB.Where("id = ?", campaign.ID).First(campaign).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch campaign"})
+		return false
+	}
+	return true
+}
+
+// notifyCampaignReview tells the creator about a review decision in the app
+// and by email.
+func notifyCampaignReview(campaign models.Campaign, message string) {
+	notifyUser(campaign.CreatorID, "campaign_review", message)
+
+	var creator models.User
+	if err := utils.DB.Select("email", "full_name").Where("id = ?", campaign.CreatorID).First(&creator).Error; err != nil {
+		return
+	}
+	sendActionEmail(creator.Email, creator.FullName, "Campaign review: "+campaign.Title, message,
+		"View your campaign", utils.FrontendURL("/campaigns/"+campaign.ID.String()))
+}
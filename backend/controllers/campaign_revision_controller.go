@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// campaignFieldChange is how one field of a campaign moved in a revision.
+type campaignFieldChange struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// campaignContent returns the fields revisions track, by the names used in
+// revision changes.
+func campaignContent(campaign models.Campaign) map[string]interface{} {
+	return map[string]interface{}{
+		"title":         campaign.Title,
+		"description":   campaign.Description,
+		"target_amount": campaign.TargetAmount,
+		"deadline":      campaign.Deadline.UTC(),
+		"category":      campaign.Category,
+	}
+}
+
+// setCampaignContent sets one tracked field from its JSON value. A null value
+// leaves the field as it is.
+func setCampaignContent(campaign *models.Campaign, field string, value json.RawMessage) error {
+	switch field {
+	case "title":
+		return json.Unmarshal(value, &campaign.Title)
+	case "description":
+		return json.Unmarshal(value, &campaign.Description)
+	case "target_amount":
+		return json.Unmarshal(value, &campaign.TargetAmount)
+	case "deadline":
+		return json.Unmarshal(value, &campaign.Deadline)
+	case "category":
+		return json.Unmarshal(value, &campaign.Category)
+	}
+	return fmt.Errorf("unknown campaign field %q", field)
+}
+
+// campaignChanges returns the tracked fields that differ between before and
+// after. A nil before lists every field, as for a new campaign.
+func campaignChanges(before *models.Campaign, after models.Campaign) map[string]campaignFieldChange {
+	var old map[string]interface{}
+	if before != nil {
+		old = campaignContent(*before)
+	}
+	changes := make(map[string]campaignFieldChange)
+	for field, value := range campaignContent(after) {
+		from, _ := json.Marshal(old[field])
+		to, _ := json.Marshal(value)
+		if !bytes.Equal(from, to) {
+			changes[field] = campaignFieldChange{From: from, To: to}
+		}
+	}
+	return changes
+}
+
+// recordCampaignRevision stores changes as a revision of the campaign by
+// editorID. Nothing is stored when nothing changed.
+func recordCampaignRevision(tx *gorm.DB, campaignID, editorID uuid.UUID, changes map[string]campaignFieldChange, revertedTo *uuid.UUID) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.CampaignRevision{
+		CampaignID: campaignID,
+		EditorID:   editorID,
+		Changes:    string(data),
+		RevertedTo: revertedTo,
+	}).Error
+}
+
+// campaignRevisionResponse renders a revision with its changes as a JSON
+// object rather than a string.
+func campaignRevisionResponse(revision models.CampaignRevision) gin.H {
+	return gin.H{
+		"id":          revision.ID,
+		"campaign_id": revision.CampaignID,
+		"editor_id":   revision.EditorID,
+		"changes":     json.RawMessage(revision.Changes),
+		"reverted_to": revision.RevertedTo,
+		"created_at":  revision.CreatedAt,
+	}
+}
+
+// ListCampaignRevisions returns the edit history of a campaign, named by ID or
+// slug, newest first, to anyone who can see the campaign.
+func ListCampaignRevisions(c *gin.Context) {
+	campaign, _, err := findCampaign(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+	page, ok := newPage(c, "campaign_revisions:"+campaign.ID.String(), newestFirst)
+	if !ok {
+		return
+	}
+
+	var revisions []models.CampaignRevision
+	result, ok := findPage(c, page, utils.DB.Model(&models.CampaignRevision{}).Where("campaign_id = ?", campaign.ID), &revisions, "Failed to fetch revisions")
+	if !ok {
+		return
+	}
+	responses := make([]gin.H, 0, len(revisions))
+	for _, revision := range revisions {
+		responses = append(responses, campaignRevisionResponse(revision))
+	}
+	c.JSON(http.StatusOK, result.Apply(gin.H{"revisions": responses}))
+}
+
+// RevertCampaignRevision puts a campaign's content back the way it was right
+// after the given revision by undoing every later one. The revert is stored
+// as a revision too, so it can be reverted in turn.
+func RevertCampaignRevision(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	// Moderators revert any campaign, whatever its status
+	campaign, _, err := lookupCampaign(func() *gorm.DB { return utils.DB.Model(&models.Campaign{}) }, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+	var target models.CampaignRevision
+	if err := utils.DB.Where("id = ? AND campaign_id = ?", c.Param("revision_id"), campaign.ID).First(&target).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	var later []models.CampaignRevision
+	if err := utils.DB.Where("campaign_id = ? AND created_at > ?", campaign.ID, target.CreatedAt).
+		Order("created_at DESC").Find(&later).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	before := campaign
+	for _, revision := range later {
+		var changes map[string]campaignFieldChange
+		if err := json.Unmarshal([]byte(revision.Changes), &changes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read revision " + revision.ID.String()})
+			return
+		}
+		for field, change := range changes {
+			if err := setCampaignContent(&campaign, field, change.From); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read revision " + revision.ID.String()})
+				return
+			}
+		}
+	}
+
+	changes := campaignChanges(&before, campaign)
+	if len(changes) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "The campaign already matches this revision"})
+		return
+	}
+
+	// Write back only the reverted fields, whose names are their columns
+	columns := make([]string, 0, len(changes))
+	for field := range changes {
+		columns = append(columns, field)
+	}
+	editorID := uuid.MustParse(userClaims.UserID)
+	if err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&campaign).Select(columns).Updates(&campaign).Error; err != nil {
+			return err
+		}
+		return recordCampaignRevision(tx, campaign.ID, editorID, changes, &target.ID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revert campaign"})
+		return
+	}
+	recordAuditChange(c, "campaign", campaign.ID.String(), before, campaign)
+
+	notifyUser(campaign.CreatorID, "campaign_reverted",
+		"An administrator reverted your campaign \""+campaign.Title+"\" to an earlier revision.")
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign reverted successfully", "campaign": campaign})
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCampaignSearchLimit = 20
+	maxCampaignSearchLimit     = 100
+)
+
+// Markers ts_headline puts around matched words. They are control characters
+// so they cannot clash with campaign text, and are turned into <mark> tags
+// once the rest of the snippet has been HTML-escaped.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+// campaignFacets are the columns search results are counted by.
+var campaignFacets = []string{"category", "status", "currency"}
+
+type campaignSearchResult struct {
+	models.Campaign
+	SearchRank     float64
+	TitleHighlight string
+	Snippet        string
+}
+
+type campaignFacet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchCampaigns runs a full-text search over campaign titles, categories
+// and descriptions. Every word matches as a prefix. Results are ranked with
+// title matches first and come with highlighted snippets, along with counts
+// per category, status and currency for the whole match.
+func SearchCampaigns(c *gin.Context) {
+	tsquery := utils.PrefixTSQuery(c.Query("q"))
+	if tsquery == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must contain at least one word"})
+		return
+	}
+
+	limit := defaultCampaignSearchLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxCampaignSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+	offset := 0
+	if value := c.Query("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+			return
+		}
+		offset = n
+	}
+
+	matches := visibleCampaigns(c, utils.DB.Model(&models.Campaign{})).
+		Where("search_vector @@ to_tsquery('english', ?)", tsquery).
+		Session(&gorm.Session{})
+
+	// Facets are counted before the facet filters apply, so clients can
+	// show how many results picking another value would give
+	facets := gin.H{}
+	for _, column := range campaignFacets {
+		counts := []campaignFacet{}
+		if err := matches.Select(column + " AS value, COUNT(*) AS count").
+			Group(column).Order("count DESC, value").
+			Scan(&counts).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search campaigns"})
+			return
+		}
+		facets[column] = counts
+	}
+
+	filtered := matches
+	for _, column := range campaignFacets {
+		if value := c.Query(column); value != "" {
+			filtered = filtered.Where(column+" = ?", value)
+		}
+	}
+	filtered = filtered.Session(&gorm.Session{})
+
+	var total int64
+	if err := filtered.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search campaigns"})
+		return
+	}
+
+	var rows []campaignSearchResult
+	if err := filtered.Select(
+		"campaigns.*, "+
+			"ts_rank_cd(search_vector, to_tsquery('english', ?)) AS search_rank, "+
+			"ts_headline('english', title, to_tsquery('english', ?), ?) AS title_highlight, "+
+			"ts_headline('english', description, to_tsquery('english', ?), ?) AS snippet",
+		tsquery,
+		tsquery, "HighlightAll=true, StartSel="+headlineStart+", StopSel="+headlineStop,
+		tsquery, "MaxWords=35, MinWords=15, MaxFragments=2, StartSel="+headlineStart+", StopSel="+headlineStop,
+	).Order("search_rank DESC, created_at DESC, id").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search campaigns"})
+		return
+	}
+
+	results := make([]gin.H, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, gin.H{
+			"campaign":        row.Campaign,
+			"rank":            row.SearchRank,
+			"title_highlight": highlightHTML(row.TitleHighlight),
+			"snippet":         highlightHTML(row.Snippet),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"total":   total,
+		"facets":  facets,
+	})
+}
+
+// highlightHTML escapes a ts_headline result and wraps the matched words in
+// <mark> tags.
+func highlightHTML(headline string) string {
+	escaped := html.EscapeString(headline)
+	return strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>").Replace(escaped)
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"backend/models"
+	"backend/slug"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// errSlugTaken is returned when a slug belongs, or used to belong, to another
+// campaign.
+var errSlugTaken = errors.New("slug is taken")
+
+// uniqueCampaignSlug returns base, or base with the lowest suffix ("-2",
+// "-3", ...) that no campaign uses or used before. Suffixed slugs are cut
+// short when needed to stay within slug.MaxLength.
+func uniqueCampaignSlug(db *gorm.DB, base string) (string, error) {
+	// Every candidate starts with this prefix, so one query finds them all
+	prefix := base
+	if len(prefix) > slug.MaxLength-6 {
+		prefix = strings.TrimRight(prefix[:slug.MaxLength-6], "-")
+	}
+
+	var taken []string
+	if err := db.Raw(`SELECT slug FROM campaigns WHERE slug LIKE ?
+		UNION SELECT slug FROM campaignslugredirects WHERE slug LIKE ?`,
+		prefix+"%", prefix+"%").Scan(&taken).Error; err != nil {
+		return "", err
+	}
+	used := make(map[string]bool, len(taken))
+	for _, s := range taken {
+		used[s] = true
+	}
+
+	candidate := base
+	for n := 2; used[candidate]; n++ {
+		suffix := "-" + strconv.Itoa(n)
+		root := base
+		if len(root)+len(suffix) > slug.MaxLength {
+			root = strings.TrimRight(root[:slug.MaxLength-len(suffix)], "-")
+		}
+		candidate = root + suffix
+	}
+	return candidate, nil
+}
+
+// slugConflict reports whether err is an insert that lost its slug to a
+// campaign created at the same time.
+func slugConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_campaigns_slug"
+}
+
+// changeCampaignSlug gives campaign a new slug and keeps the old one as a
+// redirect. A campaign may take back a slug it used before.
+func changeCampaignSlug(tx *gorm.DB, campaign *models.Campaign, newSlug string) error {
+	// Deleted campaigns keep their slugs in case they are restored
+	var count int64
+	if err := tx.Unscoped().Model(&models.Campaign{}).
+		Where("slug = ? AND id <> ?", newSlug, campaign.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := tx.Model(&models.CampaignSlugRedirect{}).
+			Where("slug = ? AND campaign_id <> ?", newSlug, campaign.ID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+	}
+	if count > 0 {
+		return errSlugTaken
+	}
+
+	if err := tx.Where("slug = ?", newSlug).Delete(&models.CampaignSlugRedirect{}).Error; err != nil {
+		return err
+	}
+	if campaign.Slug != nil {
+		if err := tx.Create(&models.CampaignSlugRedirect{Slug: *campaign.Slug, CampaignID: campaign.ID}).Error; err != nil {
+			return err
+		}
+	}
+	campaign.Slug = &newSlug
+	return nil
+}
+
+// findCampaign loads a campaign the caller may see by ID or by slug. When ref
+// is a slug the campaign used before, moved is its current slug.
+func findCampaign(c *gin.Context, ref string) (models.Campaign, string, error) {
+	return lookupCampaign(func() *gorm.DB { return visibleCampaigns(c, utils.DB.Model(&models.Campaign{})) }, ref)
+}
+
+// lookupCampaign resolves ref like findCampaign among the campaigns scope
+// returns.
+func lookupCampaign(scope func() *gorm.DB, ref string) (campaign models.Campaign, moved string, err error) {
+	if _, parseErr := uuid.Parse(ref); parseErr == nil {
+		err = scope().Where("id = ?", ref).First(&campaign).Error
+		return campaign, "", err
+	}
+
+	err = scope().Where("slug = ?", ref).First(&campaign).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return campaign, "", err
+	}
+
+	var redirect models.CampaignSlugRedirect
+	if err := utils.DB.Where("slug = ?", ref).First(&redirect).Error; err != nil {
+		return campaign, "", err
+	}
+	if err := scope().Where("id = ?", redirect.CampaignID).First(&campaign).Error; err != nil {
+		return campaign, "", err
+	}
+	if campaign.Slug != nil {
+		moved = *campaign.Slug
+	}
+	return campaign, moved, nil
+}
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"html"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const updatesOptOutTokenTTL = 365 * 24 * time.Hour
+
+// ListCampaignUpdates lists a campaign's updates, newest first. Donors-only
+// updates are left out unless the caller donated to the campaign, created it
+// or moderates campaigns.
+func ListCampaignUpdates(c *gin.Context) {
+	campaign, ok := visibleCampaign(c)
+	if !ok {
+		return
+	}
+	page, ok := newPage(c, "campaign_updates:"+campaign.ID.String(), newestFirst)
+	if !ok {
+		return
+	}
+
+	query := utils.DB.Model(&models.CampaignUpdate{}).Preload("Media").Where("campaign_id = ?", campaign.ID)
+	if !canSeeDonorUpdates(c, campaign) {
+		query = query.Where("visibility = ?", models.UpdateVisibilityPublic)
+	}
+
+	var updates []models.CampaignUpdate
+	result, ok := findPage(c, page, query, &updates, "Failed to fetch updates")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, result.Apply(gin.H{"updates": updates}))
+}
+
+// GetCampaignUpdate returns one update, with the same visibility rules as
+// ListCampaignUpdates.
+func GetCampaignUpdate(c *gin.Context) {
+	campaign, ok := visibleCampaign(c)
+	if !ok {
+		return
+	}
+	update, ok := campaignUpdate(c, campaign)
+	if !ok {
+		return
+	}
+	if update.Visibility != models.UpdateVisibilityPublic && !canSeeDonorUpdates(c, campaign) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Update not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"update": update})
+}
+
+// CreateCampaignUpdate posts an update on a campaign and notifies its donors
+// in the app and by email, except those who opted out. Only the creator or a
+// moderator can post.
+func CreateCampaignUpdate(c *gin.Context) {
+	userClaims, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title      string   `json:"title"`
+		Body       string   `json:"body"`
+		Visibility string   `json:"visibility"` // public (default) or donors
+		MediaIDs   []string `json:"media_ids"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Title == "" || input.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title and body are required"})
+		return
+	}
+	if input.Visibility == "" {
+		input.Visibility = models.UpdateVisibilityPublic
+	}
+	if !validUpdateVisibility(input.Visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Visibility must be public or donors"})
+		return
+	}
+	media, ok := updateMedia(c, campaign, input.MediaIDs)
+	if !ok {
+		return
+	}
+
+	update := models.CampaignUpdate{
+		ID:         uuid.New(),
+		CampaignID: campaign.ID,
+		AuthorID:   uuid.MustParse(userClaims.UserID),
+		Title:      input.Title,
+		Body:       input.Body,
+		Visibility: input.Visibility,
+		Media:      media,
+	}
+	if err := utils.DB.Create(&update).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create update"})
+		return
+	}
+	recordAuditChange(c, "campaign_update", update.ID.String(), nil, update)
+
+	notified, err := fanOutCampaignUpdate(campaign, update)
+	if err != nil {
+		log.Printf("Failed to notify donors of update %s: %v", update.ID, err)
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Update posted", "update": update, "notified": notified})
+}
+
+// UpdateCampaignUpdate edits an update. Donors are not notified again.
+func UpdateCampaignUpdate(c *gin.Context) {
+	_, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+	update, ok := campaignUpdate(c, campaign)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title      string    `json:"title"`
+		Body       string    `json:"body"`
+		Visibility string    `json:"visibility"`
+		MediaIDs   *[]string `json:"media_ids"` // Omit to keep the attached media
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if title := strings.TrimSpace(input.Title); title != "" {
+		update.Title = title
+	}
+	if body := strings.TrimSpace(input.Body); body != "" {
+		update.Body = body
+	}
+	if input.Visibility != "" {
+		if !validUpdateVisibility(input.Visibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Visibility must be public or donors"})
+			return
+		}
+		update.Visibility = input.Visibility
+	}
+	if input.MediaIDs != nil {
+		if update.Media, ok = updateMedia(c, campaign, *input.MediaIDs); !ok {
+			return
+		}
+	}
+
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("title", "body", "visibility", "updated_at").Updates(&update).Error; err != nil {
+			return err
+		}
+		return tx.Model(&update).Association("Media").Replace(update.Media)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save update"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Update saved", "update": update})
+}
+
+// DeleteCampaignUpdate removes an update.
+func DeleteCampaignUpdate(c *gin.Context) {
+	_, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+	update, ok := campaignUpdate(c, campaign)
+	if !ok {
+		return
+	}
+
+	// The update keeps its media in case it is restored
+	if err := utils.DB.Delete(&update).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete update"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Update deleted"})
+}
+
+// OptOutOfUpdates stops campaign update notifications and emails for the
+// user named in the signed unsubscribe link of an update email.
+func OptOutOfUpdates(c *gin.Context) {
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	claims, err := utils.ParseActionToken(input.Token, utils.PurposeUpdatesOptOut)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired link"})
+		return
+	}
+	if err := utils.DB.Model(&models.User{}).Where("id = ?", claims.UserID).Update("updates_opt_out", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "You will no longer receive campaign updates"})
+}
+
+// fanOutCampaignUpdate notifies every donor of the campaign who has not opted
+// out, and emails them in the background. It returns how many were notified.
+func fanOutCampaignUpdate(campaign models.Campaign, update models.CampaignUpdate) (int, error) {
+	var donors []models.User
+	if err := utils.DB.Where("status = ? AND updates_opt_out = ? AND id <> ?", models.UserStatusActive, false, update.AuthorID).
+		Where("id IN (?)", utils.DB.Model(&models.Donation{}).Select("donor_id").
+			Where("campaign_id = ? AND status = ?", campaign.ID, "completed")).
+		Find(&donors).Error; err != nil {
+		return 0, err
+	}
+	if len(donors) == 0 {
+		return 0, nil
+	}
+
+	donorIDs := make([]uuid.UUID, 0, len(donors))
+	for _, donor := range donors {
+		donorIDs = append(donorIDs, donor.ID)
+	}
+	if err := notifyUsers(donorIDs, "campaign_update", "New update on \""+campaign.Title+"\": "+update.Title); err != nil {
+		return 0, err
+	}
+
+	link := utils.FrontendURL("/campaigns/" + campaign.ID.String() + "/updates/" + update.ID.String())
+	subject := "Update from " + campaign.Title + ": " + update.Title
+	for _, donor := range donors {
+		token, err := utils.GenerateActionToken(donor.ID.String(), donor.Email, utils.PurposeUpdatesOptOut, updatesOptOutTokenTTL)
+		if err != nil {
+			return len(donors), err
+		}
+		message := "<strong>" + html.EscapeString(update.Title) + "</strong><br>" + html.EscapeString(excerpt(update.Body, 300)) +
+			"<br><br><small>You get this email because you donated to this campaign. " +
+			`<a href="` + utils.FrontendURL("/unsubscribe-updates?token="+token) + `">Unsubscribe from campaign updates</a></small>`
+		sendActionEmailHTML(donor.Email, donor.FullName, subject, template.HTML(message), "Read the update", link)
+	}
+	return len(donors), nil
+}
+
+// visibleCampaign loads the campaign named in the route if the caller may see it.
+func visibleCampaign(c *gin.Context) (models.Campaign, bool) {
+	var campaign models.Campaign
+	if err := visibleCampaigns(c, utils.DB.Model(&models.Campaign{})).Where("id = ?", c.Param("campaign_id")).First(&campaign).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return campaign, false
+	}
+	return campaign, true
+}
+
+// managedCampaign loads the campaign named in the route and checks that the
+// caller can edit it: an owner or editor on its team, or a moderator.
+func managedCampaign(c *gin.Context) (*utils.Claims, models.Campaign, bool) {
+	return campaignForTeam(c, models.CampaignCanEdit)
+}
+
+// campaignUpdate loads the update named in the route, with its media.
+func campaignUpdate(c *gin.Context, campaign models.Campaign) (models.CampaignUpdate, bool) {
+	var update models.CampaignUpdate
+	if err := utils.DB.Preload("Media").Where("id = ? AND campaign_id = ?", c.Param("update_id"), campaign.ID).First(&update).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Update not found"})
+		return update, false
+	}
+	return update, true
+}
+
+// canSeeDonorUpdates reports whether the caller may read donors-only updates
+// of the campaign.
+func canSeeDonorUpdates(c *gin.Context, campaign models.Campaign) bool {
+	claims, _ := c.Get("claims")
+	userClaims, _ := claims.(*utils.Claims)
+	if userClaims == nil {
+		return false
+	}
+	if campaignAllows(userClaims, campaign, models.CampaignCanView) {
+		return true
+	}
+	var donations int64
+	utils.DB.Model(&models.Donation{}).
+		Where("campaign_id = ? AND donor_id = ? AND status = ?", campaign.ID, userClaims.UserID, "completed").
+		Count(&donations)
+	return donations > 0
+}
+
+// updateMedia loads the media files to attach to an update. They must be
+// active files of the same campaign.
+func updateMedia(c *gin.Context, campaign models.Campaign, ids []string) ([]models.MediaFile, bool) {
+	media := []models.MediaFile{}
+	if len(ids) == 0 {
+		return media, true
+	}
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media id " + id})
+			return nil, false
+		}
+	}
+	if err := utils.DB.Where("id IN ? AND campaign_id = ? AND status = ?", ids, campaign.ID, "active").Find(&media).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch media files"})
+		return nil, false
+	}
+	if len(media) != len(ids) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Media files must belong to this campaign"})
+		return nil, false
+	}
+	return media, true
+}
+
+func validUpdateVisibility(visibility string) bool {
+	return visibility == models.UpdateVisibilityPublic || visibility == models.UpdateVisibilityDonors
+}
+
+// excerpt shortens text to at most n runes.
+func excerpt(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
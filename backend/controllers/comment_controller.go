@@ -91,15 +91,21 @@ func ListCommentsByCampaignID(c *gin.Context) {
 		return
 	}
 
+	page, ok := newPage(c, "campaign_comments", newestFirst)
+	if !ok {
+		return
+	}
+
 	var comments []models.Comment
 	// Preload the associated User for each comment
-	if err := utils.DB.Preload("User").Where("campaign_id = ?", campaignID).Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comments"})
+	query := utils.DB.Model(&models.Comment{}).Preload("User").Where("campaign_id = ?", campaignID)
+	result, ok := findPage(c, page, query, &comments, "Failed to retrieve comments")
+	if !ok {
 		return
 	}
 
 	// Option 1: Return the comments with the embedded user object
-	c.JSON(http.StatusOK, gin.H{"comments": comments})
+	c.JSON(http.StatusOK, result.Apply(gin.H{"comments": comments}))
 
 }
 
@@ -113,13 +119,19 @@ func ListCommentsByUserID(c *gin.Context) {
 		return
 	}
 
+	page, ok := newPage(c, "user_comments", newestFirst)
+	if !ok {
+		return
+	}
+
 	var comments []models.Comment
-	if err := utils.DB.Where("user_id = ?", userID).Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comments"})
+	query := utils.DB.Model(&models.Comment{}).Where("user_id = ?", userID)
+	result, ok := findPage(c, page, query, &comments, "Failed to retrieve comments")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, comments)
+	c.JSON(http.StatusOK, result.Apply(gin.H{"comments": comments}))
 }
 
 // UpdateComment allows the comment owner (or an admin) to update the comment content.
@@ -160,12 +172,13 @@ func UpdateComment(c *gin.Context) {
 	}
 
 	// Only allow update if the user is the owner or an admin
-	if userClaims.Role != "admin" && comment.UserID.String() != userClaims.UserID {
+	if !utils.HasPermission(userClaims.Role, utils.PermCommentModerate) && comment.UserID.String() != userClaims.UserID {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
 		return
 	}
 
 	// Update the comment
+	before := comment
 	comment.Content = input.Content
 	comment.UpdatedAt = time.Now()
 
@@ -173,6 +186,7 @@ func UpdateComment(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
 		return
 	}
+	recordAuditChange(c, "comment", comment.ID.String(), before, comment)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Comment updated successfully",
@@ -210,7 +224,7 @@ func DeleteComment(c *gin.Context) {
 	}
 
 	// Only allow deletion if the user is the owner or an admin
-	if userClaims.Role != "admin" && comment.UserID.String() != userClaims.UserID {
+	if !utils.HasPermission(userClaims.Role, utils.PermCommentModerate) && comment.UserID.String() != userClaims.UserID {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
 		return
 	}
@@ -219,6 +233,7 @@ func DeleteComment(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
 		return
 	}
+	recordAuditChange(c, "comment", comment.ID.String(), comment, nil)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
 }
@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/pagination"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// purgeBatchSize bounds how many rows of a table one purge statement removes.
+const purgeBatchSize = 500
+
+// deletedKind is a model whose rows are soft deleted. Deleted rows are hidden
+// by gorm's default scope, can be listed and restored by an admin holding
+// permission, and are purged by PurgeDeletedRecords once they are older than
+// the retention period.
+type deletedKind struct {
+	resource   string // Table name, used in the admin routes
+	auditType  string
+	permission string
+	model      func() interface{}
+	list       func(c *gin.Context, page *pagination.Page) (gin.H, bool)
+	// conflict, when set, explains why a deleted row cannot be restored
+	conflict func(tx *gorm.DB, id uuid.UUID) (string, error)
+	// purgeable, when set, narrows down the deleted rows that may be purged
+	purgeable string
+	// beforePurge, when set, removes what refers to the rows about to go
+	beforePurge func(tx *gorm.DB, ids []uuid.UUID) error
+}
+
+func newDeletedKind[T any](resource, auditType, permission string) deletedKind {
+	return deletedKind{
+		resource:   resource,
+		auditType:  auditType,
+		permission: permission,
+		model:      func() interface{} { return new(T) },
+		list:       listDeleted[T],
+	}
+}
+
+// deletedKinds lists every soft-deleted model. Purging goes in this order,
+// so rows that refer to a campaign go before the campaign.
+var deletedKinds = func() []deletedKind {
+	updates := newDeletedKind[models.CampaignUpdate]("campaignupdates", "campaign_update", utils.PermCampaignModerate)
+	updates.beforePurge = func(tx *gorm.DB, ids []uuid.UUID) error {
+		return tx.Exec("DELETE FROM campaignupdatemedia WHERE campaign_update_id IN ?", ids).Error
+	}
+	media := newDeletedKind[models.MediaFile]("mediafiles", "media_file", utils.PermCampaignModerate)
+	media.beforePurge = func(tx *gorm.DB, ids []uuid.UUID) error {
+		return tx.Exec("DELETE FROM campaignupdatemedia WHERE media_file_id IN ?", ids).Error
+	}
+	milestones := newDeletedKind[models.CampaignMilestone]("campaignmilestones", "campaign_milestone", utils.PermCampaignModerate)
+	milestones.conflict = milestoneRestoreConflict
+	tiers := newDeletedKind[models.RewardTier]("rewardtiers", "reward_tier", utils.PermCampaignModerate)
+	tiers.purgeable = "NOT EXISTS (SELECT 1 FROM rewardclaims WHERE rewardclaims.reward_tier_id = rewardtiers.id)"
+	// Campaigns with donations or withdrawals are kept for the accounts, and
+	// those with support tickets for the ticket history
+	campaigns := newDeletedKind[models.Campaign]("campaigns", "campaign", utils.PermCampaignModerate)
+	campaigns.purgeable = `NOT EXISTS (SELECT 1 FROM donations WHERE donations.campaign_id = campaigns.id)
+		AND NOT EXISTS (SELECT 1 FROM withdrawals WHERE withdrawals.campaign_id = campaigns.id)
+		AND NOT EXISTS (SELECT 1 FROM supporttickets WHERE supporttickets.campaign_id = campaigns.id)`
+
+	return []deletedKind{
+		newDeletedKind[models.Comment]("comments", "comment", utils.PermCommentModerate),
+		newDeletedKind[models.Notification]("notifications", "notification", utils.PermNotificationManage),
+		newDeletedKind[models.SupportTicket]("supporttickets", "support_ticket", utils.PermSupportManage),
+		newDeletedKind[models.PaymentTransaction]("paymenttransactions", "payment_transaction", utils.PermPaymentManage),
+		newDeletedKind[models.Withdrawal]("withdrawals", "withdrawal", utils.PermWithdrawalDelete),
+		updates,
+		media,
+		milestones,
+		tiers,
+		campaigns,
+	}
+}()
+
+// deletedKindFor looks up the model named in the route and checks that the
+// caller may restore its rows.
+func deletedKindFor(c *gin.Context) (deletedKind, bool) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return deletedKind{}, false
+	}
+	for _, kind := range deletedKinds {
+		if kind.resource != c.Param("resource") {
+			continue
+		}
+		if !utils.HasPermission(userClaims.Role, kind.permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return kind, false
+		}
+		return kind, true
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Unknown resource " + c.Param("resource")})
+	return deletedKind{}, false
+}
+
+func listDeleted[T any](c *gin.Context, page *pagination.Page) (gin.H, bool) {
+	var records []T
+	query := utils.DB.Unscoped().Model(new(T)).Where("deleted_at IS NOT NULL")
+	result, ok := findPage(c, page, query, &records, "Failed to fetch deleted records")
+	if !ok {
+		return nil, false
+	}
+	return result.Apply(gin.H{"records": records}), true
+}
+
+// ListDeletedRecords lists the deleted rows of one model, most recently
+// deleted first.
+func ListDeletedRecords(c *gin.Context) {
+	kind, ok := deletedKindFor(c)
+	if !ok {
+		return
+	}
+	page, ok := newPage(c, "deleted:"+kind.resource, pagination.Key{Column: "deleted_at", Desc: true})
+	if !ok {
+		return
+	}
+	response, ok := kind.list(c, page)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreDeletedRecord brings back a deleted row that has not been purged
+// yet.
+func RestoreDeletedRecord(c *gin.Context) {
+	kind, ok := deletedKindFor(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted record not found"})
+		return
+	}
+
+	record := kind.model()
+	var conflict string
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(record).Error; err != nil {
+			return err
+		}
+		if kind.conflict != nil {
+			var err error
+			if conflict, err = kind.conflict(tx, id); err != nil || conflict != "" {
+				return err
+			}
+		}
+		return tx.Unscoped().Model(record).Update("deleted_at", nil).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted record not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore record"})
+		return
+	}
+	if conflict != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		return
+	}
+	recordAuditChange(c, kind.auditType, id.String(), gin.H{"deleted": true}, gin.H{"deleted": false})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Record restored", "record": record})
+}
+
+// milestoneRestoreConflict refuses to restore a milestone whose amount was
+// given to another milestone of the campaign in the meantime.
+func milestoneRestoreConflict(tx *gorm.DB, id uuid.UUID) (string, error) {
+	var milestone models.CampaignMilestone
+	if err := tx.Unscoped().Where("id = ?", id).First(&milestone).Error; err != nil {
+		return "", err
+	}
+	var taken int64
+	if err := tx.Model(&models.CampaignMilestone{}).
+		Where("campaign_id = ? AND amount = ?", milestone.CampaignID, milestone.Amount).
+		Count(&taken).Error; err != nil {
+		return "", err
+	}
+	if taken > 0 {
+		return "The campaign already has a milestone for this amount", nil
+	}
+	return "", nil
+}
+
+// PurgeDeletedRecords permanently removes rows deleted more than
+// SOFT_DELETE_RETENTION_DAYS ago (default 30). The job scheduler runs it
+// periodically.
+func PurgeDeletedRecords(ctx context.Context) error {
+	retention := time.Duration(utils.GetEnvInt("SOFT_DELETE_RETENTION_DAYS", 30)) * 24 * time.Hour
+	cutoff := time.Now().Add(-retention)
+	db := utils.DB.WithContext(ctx)
+
+	for _, kind := range deletedKinds {
+		purged, err := purgeDeleted(db, kind, cutoff)
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			log.Printf("purge: removed %d deleted %s", purged, kind.resource)
+		}
+	}
+	return nil
+}
+
+// purgeDeleted removes the rows of one model deleted before cutoff, in
+// batches.
+func purgeDeleted(db *gorm.DB, kind deletedKind, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		var ids []uuid.UUID
+		query := db.Unscoped().Model(kind.model()).Where("deleted_at < ?", cutoff)
+		if kind.purgeable != "" {
+			query = query.Where(kind.purgeable)
+		}
+		if err := query.Limit(purgeBatchSize).Pluck("id", &ids).Error; err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if kind.beforePurge != nil {
+				if err := kind.beforePurge(tx, ids); err != nil {
+					return err
+				}
+			}
+			result := tx.Unscoped().Where("id IN ?", ids).Delete(kind.model())
+			total += result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return total, err
+		}
+		if len(ids) < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
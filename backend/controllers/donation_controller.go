@@ -1,13 +1,19 @@
 package controllers
 
 import (
-	// "time"
+	"backend/filter"
 	"backend/models"
+	"backend/pagination"
 	"backend/utils"
+	"errors"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func MakeDonation(c *gin.Context) {
@@ -20,6 +26,10 @@ func MakeDonation(c *gin.Context) {
 		Currency    string  `json:"currency" binding:"required"`
 		Message     string  `json:"message,omitempty"`
 		IsAnonymous bool    `json:"is_anonymous"`
+		// Optional reward; shipping holds the donor's phone and, for rewards
+		// that ship, their address
+		RewardTierID string              `json:"reward_tier_id,omitempty"`
+		Shipping     rewardShippingInput `json:"shipping"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -32,6 +42,20 @@ func MakeDonation(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
 		return
 	}
+	// The lifecycle job closes campaigns past their deadline, but it runs
+	// periodically, so check the deadline here as well
+	if campaign.Status != models.CampaignStatusActive || time.Now().UTC().After(campaign.Deadline) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Campaign is not accepting donations"})
+		return
+	}
+
+	var tier *models.RewardTier
+	if input.RewardTierID != "" {
+		var ok bool
+		if tier, ok = donationRewardTier(c, campaign, input.RewardTierID, input.Amount, input.Currency, input.Shipping); !ok {
+			return
+		}
+	}
 
 	// Check if the donor already exists
 	var donor models.User
@@ -62,6 +86,7 @@ func MakeDonation(c *gin.Context) {
 
 	// Create the donation
 	donation := models.Donation{
+		ID:          uuid.New(),
 		CampaignID:  uuid.MustParse(input.CampaignID),
 		DonorID:     donor.ID,
 		Amount:      input.Amount,
@@ -71,20 +96,49 @@ func MakeDonation(c *gin.Context) {
 		Status:      "completed",
 	}
 
-	// Save the donation to the database
-	if err := utils.DB.Create(&donation).Error; err != nil {
+	// Save the donation, claim its reward and add it to the campaign together,
+	// so a sold-out reward leaves nothing behind
+	var claim *models.RewardClaim
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&donation).Error; err != nil {
+			return err
+		}
+		if tier != nil {
+			var err error
+			if claim, err = claimReward(tx, donation, *tier, donor.Email, input.Shipping); err != nil {
+				return err
+			}
+		}
+
+		// Update the campaign's current amount. Only touch that column so a
+		// status change made meanwhile, e.g. by the lifecycle job, is kept.
+		// The new total comes back from the update so milestones are checked
+		// against it rather than a stale read
+		return tx.Model(&campaign).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "current_amount"}}}).
+			Update("current_amount", gorm.Expr("current_amount + ?", input.Amount)).Error
+	})
+	if errors.Is(err, errRewardSoldOut) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This reward is sold out"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create donation"})
 		return
 	}
 
-	// Update the campaign's current amount
-	campaign.CurrentAmount += input.Amount
-	if err := utils.DB.Save(&campaign).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update campaign"})
-		return
+	// Donors follow the campaigns they give to, unless they gave anonymously,
+	// which must not leave a link between the donor and the campaign
+	if !input.IsAnonymous {
+		if err := followCampaign(campaign.ID, donor.ID); err != nil {
+			log.Printf("Failed to add %s as a follower of campaign %s: %v", donor.ID, campaign.ID, err)
+		}
+	}
+	if err := reachMilestones(campaign); err != nil {
+		log.Printf("Failed to check milestones of campaign %s: %v", campaign.ID, err)
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message":  "Donation successful",
 		"donation": donation,
 		"donor": gin.H{
@@ -92,7 +146,11 @@ func MakeDonation(c *gin.Context) {
 			"email":     donor.Email,
 			"full_name": donor.FullName,
 		},
-	})
+	}
+	if claim != nil {
+		response["reward_claim"] = claim
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 
@@ -122,60 +180,72 @@ func MakeDonation(c *gin.Context) {
 
 
 func ListCampaignDonations(c *gin.Context) {
-	// Get the campaign ID from the request parameters
-	campaignID := c.Param("id")
-
-	// Ensure the campaign exists
-	var campaign models.Campaign
-	if err := utils.DB.Where("id = ?", campaignID).First(&campaign).Error; err != nil {
+	// Ensure the campaign exists and the caller may see it
+	campaign, _, err := findCampaign(c, c.Param("id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
 		return
 	}
 
-	// Fetch all donations related to the campaign along with the donor information
-	var donations []models.Donation
-	if err := utils.DB.Preload("Donor").
-		Where("campaign_id = ?", campaignID).
-		Find(&donations).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch donations"})
+	page, ok := newPage(c, "campaign_donations", newestFirst)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"donations": donations})
-}
-
-
-
-func ListUserDonations(c *gin.Context) {
-	// Retrieve claims from the context
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	// Fetch the campaign's donations along with the donors' names
+	var donations []models.Donation
+	query := utils.DB.Model(&models.Donation{}).
+		Preload("Donor", func(db *gorm.DB) *gorm.DB { return db.Select("id", "full_name") }).
+		Where("campaign_id = ?", campaign.ID)
+	result, ok := findPage(c, page, query, &donations, "Failed to fetch donations")
+	if !ok {
 		return
 	}
 
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-		return
+	responses := make([]gin.H, 0, len(donations))
+	for _, donation := range donations {
+		responses = append(responses, publicDonationResponse(donation))
 	}
+	c.JSON(http.StatusOK, result.Apply(gin.H{"donations": responses}))
+}
 
-	// Check if the user is an admin
-	if userClaims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
-		return
+// publicDonationResponse renders a donation for the campaign's public list.
+// It names the donor but never their contact details, and says nothing about
+// who made an anonymous donation.
+func publicDonationResponse(donation models.Donation) gin.H {
+	response := gin.H{
+		"id":           donation.ID,
+		"campaign_id":  donation.CampaignID,
+		"donor_id":     nil,
+		"donor_name":   nil,
+		"amount":       donation.Amount,
+		"currency":     donation.Currency,
+		"message":      donation.Message,
+		"is_anonymous": donation.IsAnonymous,
+		"status":       donation.Status,
+		"created_at":   donation.CreatedAt,
+	}
+	if !donation.IsAnonymous {
+		response["donor_id"] = donation.DonorID
+		response["donor_name"] = donation.Donor.FullName
 	}
+	return response
+}
+
+
 
+func ListUserDonations(c *gin.Context) {
 	// Query parameters for filtering, sorting, and grouping
 	campaignID := c.Query("campaign_id") // Filter by campaign
 	minAmount := c.Query("min_amount")  // Filter by minimum donation amount
 	maxAmount := c.Query("max_amount")  // Filter by maximum donation amount
-	sortBy := c.Query("sort_by")        // Column to sort by (e.g., "amount" or "created_at")
-	order := c.Query("order")           // Order of sorting (e.g., "asc" or "desc")
 	groupBy := c.Query("group_by")      // Group by column (e.g., "campaign_id")
 
 	// Initialize the query
-	query := utils.DB.Model(&models.Donation{})
+	query, ok := applyFilter(c, utils.DB.Model(&models.Donation{}), donationFields)
+	if !ok {
+		return
+	}
 
 	// Apply filters
 	if campaignID != "" {
@@ -188,52 +258,56 @@ func ListUserDonations(c *gin.Context) {
 		query = query.Where("amount <= ?", maxAmount)
 	}
 
-	// Apply grouping
-	if groupBy != "" {
-		query = query.Group(groupBy)
+	// Apply sorting
+	sort, ok := listSort(c, donationFields)
+	if !ok {
+		return
 	}
 
-	// Apply sorting
-	if sortBy != "" {
-		// Default to ascending order if order is not specified or invalid
-		if order != "asc" && order != "desc" {
-			order = "asc"
+	// Apply grouping: donations of the same group are listed together
+	if groupBy != "" {
+		column, ok := donationGroupColumns[groupBy]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group_by"})
+			return
 		}
-		query = query.Order(sortBy + " " + order)
+		sort = append([]pagination.Key{{Column: column}}, sort...)
+	}
+
+	page, ok := newPage(c, "donations", sort...)
+	if !ok {
+		return
 	}
 
 	// Fetch donations
 	var donations []models.Donation
-	if err := query.Find(&donations).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch donations"})
+	result, ok := findPage(c, page, query, &donations, "Failed to fetch donations")
+	if !ok {
 		return
 	}
 
 	// Return the donations
-	c.JSON(http.StatusOK, gin.H{"donations": donations})
+	c.JSON(http.StatusOK, result.Apply(gin.H{"donations": donations}))
 }
 
+// donationFields are the fields ListUserDonations can filter and sort by.
+var donationFields = filter.Fields{
+	"campaign_id": {Column: "campaign_id", Kind: filter.UUID},
+	"donor_id":    {Column: "donor_id", Kind: filter.UUID},
+	"amount":      {Column: "amount", Kind: filter.Number, Sortable: true},
+	"currency":    {Column: "currency", Kind: filter.String},
+	"status":      {Column: "status", Kind: filter.String},
+	"created_at":  {Column: "created_at", Kind: filter.Time, Sortable: true},
+}
 
-func UpdateDonation(c *gin.Context) {
-	// Retrieve claims from the context
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-		return
-	}
-
-	// Check if the user is an admin
-	if userClaims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
-		return
-	}
+// donationGroupColumns are the columns ListUserDonations can group by.
+var donationGroupColumns = map[string]string{
+	"campaign_id": "campaign_id",
+	"currency":    "currency",
+	"status":      "status",
+}
 
+func UpdateDonation(c *gin.Context) {
 	// Get the donation ID from the URL parameter
 	donationID := c.Param("id")
 
@@ -268,6 +342,8 @@ func UpdateDonation(c *gin.Context) {
 		return
 	}
 
+	before := donation
+
 	// Calculate the amount difference
 	amountDifference := 0.0
 	if input.Amount != 0 {
@@ -287,18 +363,21 @@ func UpdateDonation(c *gin.Context) {
 		donation.Status = input.Status
 	}
 
-	// Save the updated donation
-	if err := utils.DB.Save(&donation).Error; err != nil {
+	// Save the updated donation and move the campaign's current amount by the
+	// difference, without writing back the rest of the campaign as read above
+	if err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&donation).Error; err != nil {
+			return err
+		}
+		if amountDifference == 0 {
+			return nil
+		}
+		return tx.Model(&campaign).UpdateColumn("current_amount", gorm.Expr("current_amount + ?", amountDifference)).Error
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update donation"})
 		return
 	}
-
-	// Update the campaign's current amount
-	campaign.CurrentAmount += amountDifference
-	if err := utils.DB.Save(&campaign).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update campaign"})
-		return
-	}
+	recordAuditChange(c, "donation", donation.ID.String(), before, donation)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Donation updated successfully",
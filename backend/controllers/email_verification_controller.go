@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTokenTTL = 48 * time.Hour
+	accountClaimTokenTTL      = time.Hour
+)
+
+var errAccountAlreadyClaimed = errors.New("account already claimed")
+
+// actionEmailTemplate is a generic single-button email used for account
+// flows such as verifying an address or claiming a guest account.
+var actionEmailTemplate = template.Must(template.New("action_email").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width,initial-scale=1">
+  <title>{{.Title}}</title>
+  <style>
+    body, table, td, a { -webkit-text-size-adjust:100%; -ms-text-size-adjust:100%; }
+    table { border-collapse:collapse!important; }
+    body { margin:0!important; padding:0!important; width:100%!important;
+           font-family:'Helvetica Neue',Helvetica,Arial,sans-serif;
+           background-color:#f4f4f4; color:#333; }
+    a { color:#1a73e8; text-decoration:none; }
+    .email-container { max-width:600px; margin:auto; background:#fff;
+                       border-radius:8px; overflow:hidden; }
+    .header { background:#1a73e8; padding:20px; text-align:center; }
+    .header h1 { color:#fff; margin:0; font-size:28px; letter-spacing:1px; }
+    .content { padding:30px; }
+    .content h2 { margin:0 0 16px; font-size:24px; color:#333; }
+    .content p { margin:0 0 16px; line-height:1.6; }
+    .btn { display:inline-block; padding:12px 24px; background:#1a73e8;
+           color:#fff!important; border-radius:4px; font-weight:bold; }
+    .footer { background:#f4f4f4; padding:20px; text-align:center;
+              font-size:12px; color:#777; }
+  </style>
+</head>
+<body>
+  <table width="100%" cellpadding="0" cellspacing="0">
+    <tr><td align="center">
+      <div class="email-container">
+        <div class="header">
+          <h1>Impacta</h1>
+        </div>
+        <div class="content">
+          <h2>Hi {{.Name}},</h2>
+          <p>{{.Message}}</p>
+          <p style="text-align:center;">
+            <a href="{{.ActionURL}}" class="btn">{{.ActionLabel}}</a>
+          </p>
+          <p>If you did not request this, you can safely ignore this email.</p>
+          <p>Cheers,<br>The Impacta Team</p>
+        </div>
+        <div class="footer">
+          <p>&copy; 2025 Impacta Inc. All rights reserved.</p>
+        </div>
+      </div>
+    </td></tr>
+  </table>
+</body>
+</html>`))
+
+// actionEmail fills actionEmailTemplate.
+type actionEmail struct {
+	Title       string
+	Name        string
+	Message     template.HTML
+	ActionLabel string
+	ActionURL   string
+}
+
+// sendActionEmail sends actionEmailTemplate in the background. Every input is
+// plain text and is escaped.
+func sendActionEmail(to, name, subject, message, actionLabel, actionURL string) {
+	sendActionEmailHTML(to, name, subject, template.HTML(template.HTMLEscapeString(message)), actionLabel, actionURL)
+}
+
+// sendActionEmailHTML is sendActionEmail for a message that is already HTML.
+// The caller must escape anything in it that users wrote.
+func sendActionEmailHTML(to, name, subject string, message template.HTML, actionLabel, actionURL string) {
+	go func() {
+		var body strings.Builder
+		if err := actionEmailTemplate.Execute(&body, actionEmail{
+			Title:       subject,
+			Name:        name,
+			Message:     message,
+			ActionLabel: actionLabel,
+			ActionURL:   actionURL,
+		}); err != nil {
+			log.Printf("error rendering %q email: %v", subject, err)
+			return
+		}
+		if err := utils.SendEmail(to, subject, body.String()); err != nil {
+			log.Printf("error sending %q email to %s: %v", subject, to, err)
+		}
+	}()
+}
+
+// sendVerificationEmail emails the user a signed link proving control of their address.
+func sendVerificationEmail(user models.User) error {
+	token, err := utils.GenerateActionToken(user.ID.String(), user.Email, utils.PurposeEmailVerification, emailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+	sendActionEmail(user.Email, user.FullName,
+		"Verify your Impacta email address",
+		"Please confirm that this is your email address so we can keep your account secure.",
+		"Verify Email",
+		utils.FrontendURL("/verify-email?token="+token))
+	return nil
+}
+
+// sendAccountClaimEmail emails a guest donor a signed link that lets them set a password.
+func sendAccountClaimEmail(user models.User) error {
+	token, err := utils.GenerateActionToken(user.ID.String(), user.Email, utils.PurposeAccountClaim, accountClaimTokenTTL)
+	if err != nil {
+		return err
+	}
+	sendActionEmail(user.Email, user.FullName,
+		"Claim your Impacta account",
+		"You have donated on Impacta as a guest. Set a password to claim your account and see all your donations in one place.",
+		"Claim Account",
+		utils.FrontendURL("/claim-account?token="+token))
+	return nil
+}
+
+// VerifyEmail marks the user's email as verified using the signed link from the verification email.
+func VerifyEmail(c *gin.Context) {
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actionClaims, err := utils.ParseActionToken(input.Token, utils.PurposeEmailVerification)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", actionClaims.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+	// The link only proves control of the address it was sent to.
+	if user.Email != actionClaims.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+
+	if !user.EmailVerified {
+		now := time.Now()
+		if err := utils.DB.Model(&user).Updates(map[string]interface{}{
+			"email_verified":    true,
+			"email_verified_at": now,
+		}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerificationEmail sends a fresh verification link to the authenticated user.
+func ResendVerificationEmail(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userClaims, ok := claims.(*utils.Claims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", userClaims.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.EmailVerified {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate verification token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+// RequestAccountClaim emails a claim link to a guest donor account created by MakeDonation.
+// It always responds with the same message so it cannot be used to probe which emails exist.
+func RequestAccountClaim(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	err := utils.DB.Where("email = ?", input.Email).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("error looking up user for account claim: %v", err)
+	}
+	// Only passwordless guest accounts can be claimed.
+	if err == nil && user.PasswordHash == "" {
+		if err := sendAccountClaimEmail(user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate claim token"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If a guest account exists for that email, a claim link has been sent"})
+}
+
+// ClaimAccount sets a password on a guest donor account after the donor has
+// proven control of the email address via the signed claim link.
+func ClaimAccount(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=8"`
+		FullName string `json:"full_name,omitempty"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actionClaims, err := utils.ParseActionToken(input.Token, utils.PurposeAccountClaim)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired claim token"})
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", actionClaims.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired claim token"})
+		return
+	}
+	if user.Email != actionClaims.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired claim token"})
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"password_hash":     string(hashed),
+		"email_verified":    true,
+		"email_verified_at": time.Now(),
+		"token_version":     gorm.Expr("token_version + 1"),
+	}
+	if input.FullName != "" {
+		updates["full_name"] = input.FullName
+	}
+
+	// Guarding on an empty password hash makes the claim link single-use.
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ? AND password_hash = ''", user.ID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAccountAlreadyClaimed
+		}
+		// Drop any sessions that were handed out while the account had no password.
+		return revokeUserRefreshTokens(tx, user.ID)
+	})
+	if err != nil {
+		if errors.Is(err, errAccountAlreadyClaimed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Account has already been claimed"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim account"})
+		}
+		return
+	}
+
+	if err := utils.DB.Where("id = ?", user.ID).First(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+
+	session, err := issueSession(user, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	response := session.response()
+	response["message"] = "Account claimed successfully"
+	c.JSON(http.StatusOK, response)
+}
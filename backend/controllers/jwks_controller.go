@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publishes the public JWT verification keys as a JSON Web Key Set.
+// HS256 keys are shared secrets and are never included.
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.PublicJWKS())
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Failures older than this are forgotten
+	loginFailureWindow = 15 * time.Minute
+	// The first few failures are free, so a mistyped password is not
+	// punished; after that each failure doubles the wait
+	loginFreeFailures = 3
+	loginBackoffBase  = time.Second
+	loginBackoffMax   = time.Minute
+)
+
+// loginThrottle is the limit applied to one kind of throttle key.
+type loginThrottle struct {
+	prefix      string
+	maxFailures int
+	lockout     time.Duration
+}
+
+// accountThrottle limits failed logins per email address
+// (LOGIN_MAX_FAILURES, LOGIN_LOCKOUT_MINUTES).
+func accountThrottle() loginThrottle {
+	return loginThrottle{
+		prefix:      "account:",
+		maxFailures: utils.GetEnvInt("LOGIN_MAX_FAILURES", 10),
+		lockout:     time.Duration(utils.GetEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+	}
+}
+
+// ipThrottle limits failed logins per client IP across all accounts
+// (LOGIN_IP_MAX_FAILURES, LOGIN_LOCKOUT_MINUTES).
+func ipThrottle() loginThrottle {
+	return loginThrottle{
+		prefix:      "ip:",
+		maxFailures: utils.GetEnvInt("LOGIN_IP_MAX_FAILURES", 50),
+		lockout:     time.Duration(utils.GetEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+	}
+}
+
+func (t loginThrottle) key(value string) string {
+	return t.prefix + strings.ToLower(strings.TrimSpace(value))
+}
+
+// backoff returns how long to refuse attempts after the given number of
+// consecutive failures.
+func (t loginThrottle) backoff(failures int) time.Duration {
+	if failures >= t.maxFailures {
+		return t.lockout
+	}
+	if failures <= loginFreeFailures {
+		return 0
+	}
+	delay := loginBackoffBase * time.Duration(math.Pow(2, float64(failures-loginFreeFailures-1)))
+	if delay > loginBackoffMax {
+		delay = loginBackoffMax
+	}
+	return delay
+}
+
+// loginRetryAfter returns how long the caller must wait before another login
+// attempt is accepted for any of the keys, or zero.
+func loginRetryAfter(keys ...string) (time.Duration, error) {
+	var throttles []models.LoginThrottle
+	if err := utils.DB.Where("key IN ? AND locked_until > ?", keys, time.Now()).Find(&throttles).Error; err != nil {
+		return 0, err
+	}
+	var wait time.Duration
+	for _, t := range throttles {
+		if d := time.Until(*t.LockedUntil); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// recordLoginFailure counts a failed attempt against the key and reports
+// whether it has just locked the key out.
+func recordLoginFailure(throttle loginThrottle, key string) (bool, error) {
+	var locked bool
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginThrottle{Key: key, LastFailureAt: now}).Error; err != nil {
+			return err
+		}
+		var t models.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&t).Error; err != nil {
+			return err
+		}
+
+		lockoutOver := t.Failures >= throttle.maxFailures && t.LockedUntil != nil && now.After(*t.LockedUntil)
+		if lockoutOver || now.Sub(t.LastFailureAt) > loginFailureWindow {
+			t.Failures = 0
+		}
+		t.Failures++
+		t.LastFailureAt = now
+		lockedUntil := now.Add(throttle.backoff(t.Failures))
+		t.LockedUntil = &lockedUntil
+		locked = t.Failures == throttle.maxFailures
+		return tx.Save(&t).Error
+	})
+	return locked, err
+}
+
+// clearLoginFailures forgets the failures counted against the key.
+func clearLoginFailures(key string) error {
+	return utils.DB.Where("key = ?", key).Delete(&models.LoginThrottle{}).Error
+}
+
+// forgetLoginFailures clears the account's failures once it has been given a
+// full session. A correct password alone is not enough when a second factor
+// is still to come, as the failures also limit guesses at the code.
+func forgetLoginFailures(user models.User) {
+	if err := clearLoginFailures(accountThrottle().key(user.Email)); err != nil {
+		log.Println("Failed to clear failed logins:", err)
+	}
+}
+
+// checkLoginThrottle responds with 429 and returns false while the account or
+// the client IP is backing off or locked out.
+func checkLoginThrottle(c *gin.Context, email string) bool {
+	wait, err := loginRetryAfter(accountThrottle().key(email), ipThrottle().key(c.ClientIP()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+		return false
+	}
+	if wait > 0 {
+		seconds := int(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "Too many failed login attempts, please try again later",
+			"retry_after": seconds,
+		})
+		return false
+	}
+	return true
+}
+
+// recordFailedLogin counts a failure against both the account and the client
+// IP. When the account has just been locked out its owner is told by email.
+func recordFailedLogin(c *gin.Context, email string, user *models.User) {
+	account := accountThrottle()
+	locked, err := recordLoginFailure(account, account.key(email))
+	if err != nil {
+		log.Println("Failed to record failed login:", err)
+	}
+	ip := ipThrottle()
+	if _, err := recordLoginFailure(ip, ip.key(c.ClientIP())); err != nil {
+		log.Println("Failed to record failed login:", err)
+	}
+	if locked && user != nil {
+		sendLockoutEmail(*user, account.lockout)
+	}
+}
+
+// sendLockoutEmail warns the user that their account was locked out.
+func sendLockoutEmail(user models.User, lockout time.Duration) {
+	sendActionEmail(user.Email, user.FullName,
+		"Your Impacta account has been locked",
+		fmt.Sprintf("We blocked sign-ins to your account for %d minutes after several failed login attempts. "+
+			"If this wasn't you, we recommend resetting your password.", int(lockout.Minutes())),
+		"Reset Password",
+		utils.FrontendURL("/forgot-password"))
+}
+
+// UnlockUser clears the failed login attempts of a locked-out account (admin only).
+func UnlockUser(c *gin.Context) {
+	var user models.User
+	if err := utils.DB.Where("id = ?", c.Param("id")).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+
+	key := accountThrottle().key(user.Email)
+	var throttle models.LoginThrottle
+	if err := utils.DB.Where("key = ?", key).Limit(1).Find(&throttle).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+	if err := clearLoginFailures(key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+	recordAuditChange(c, "user", user.ID.String(),
+		gin.H{"failures": throttle.Failures, "locked_until": throttle.LockedUntil},
+		gin.H{"failures": 0, "locked_until": nil})
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
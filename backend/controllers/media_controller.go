@@ -12,27 +12,8 @@ import (
 )
 
 // CreateMediaFile creates a new media file record.
-// Only authenticated users (admin or campaign_creator) can add media files.
+// The route requires the media:create permission.
 func CreateMediaFile(c *gin.Context) {
-	// Retrieve claims from the context
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-		return
-	}
-
-	// Optionally, you can restrict this endpoint to specific roles
-	if userClaims.Role != "admin" && userClaims.Role != "campaign_creator" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
-		return
-	}
-
 	// Bind input JSON
 	var input struct {
 		CampaignID string `json:"campaign_id" binding:"required"`
@@ -98,13 +79,19 @@ func ListMediaFilesByCampaignID(c *gin.Context) {
 		return
 	}
 
+	page, ok := newPage(c, "campaign_media_files", newestFirst)
+	if !ok {
+		return
+	}
+
 	var mediaFiles []models.MediaFile
-	if err := utils.DB.Where("campaign_id = ?", campaignID).Find(&mediaFiles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve media files"})
+	query := utils.DB.Model(&models.MediaFile{}).Where("campaign_id = ?", campaignID)
+	result, ok := findPage(c, page, query, &mediaFiles, "Failed to retrieve media files")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, mediaFiles)
+	c.JSON(http.StatusOK, result.Apply(gin.H{"media_files": mediaFiles}))
 }
 
 // ListMediaFilesByUserID returns media files for campaigns belonging to a specific user.
@@ -117,23 +104,26 @@ func ListMediaFilesByUserID(c *gin.Context) {
 		return
 	}
 
+	page, ok := newPage(c, "user_media_files", newestFirst)
+	if !ok {
+		return
+	}
+
 	// Join media_files with campaigns to filter by the campaign creator.
 	var mediaFiles []models.MediaFile
-	err = utils.DB.
+	query := utils.DB.Model(&models.MediaFile{}).
 	Joins("JOIN campaigns ON campaigns.id = mediafiles.campaign_id").
-	Where("campaigns.creator_id = ?", userID).
-	Find(&mediaFiles).Error
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve media files"})
+	Where("campaigns.creator_id = ? AND campaigns.deleted_at IS NULL", userID)
+	result, ok := findPage(c, page, query, &mediaFiles, "Failed to retrieve media files")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, mediaFiles)
+	c.JSON(http.StatusOK, result.Apply(gin.H{"media_files": mediaFiles}))
 }
 
 // BulkDeleteMediaFiles deletes multiple media files provided by a list of IDs.
-// Only the campaign owner or an admin is allowed to delete the media files.
+// Only the campaign's owners and editors, or a moderator, can delete them.
 func BulkDeleteMediaFiles(c *gin.Context) {
 	// Retrieve claims from the context
 	claims, exists := c.Get("claims")
@@ -182,8 +172,8 @@ func BulkDeleteMediaFiles(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify media file ownership"})
 			return
 		}
-		// Allow deletion if current user is an admin or the creator of the campaign.
-		if userClaims.Role != "admin" && campaign.CreatorID.String() != userClaims.UserID {
+		// Allow deletion if the current user is a moderator or can edit the campaign.
+		if !campaignAllows(userClaims, campaign, models.CampaignCanEdit) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete one or more media files"})
 			return
 		}
@@ -194,6 +184,9 @@ func BulkDeleteMediaFiles(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media files"})
 		return
 	}
+	for _, media := range mediaFiles {
+		recordAuditChange(c, "media_file", media.ID.String(), media, nil)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Media files deleted successfully"})
 }
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 	"time"
 
@@ -11,6 +12,43 @@ import (
 	"gorm.io/gorm"
 )
 
+// notifyUser leaves an in-app notification for a user. Failures are logged
+// rather than failing the action that triggered the notification.
+func notifyUser(userID uuid.UUID, notificationType, content string) {
+	notification := models.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Type:      notificationType,
+		Content:   content,
+		Status:    "unread",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := utils.DB.Create(&notification).Error; err != nil {
+		log.Printf("Failed to notify user %s: %v", userID, err)
+	}
+}
+
+// notifyUsers leaves the same in-app notification for several users at once.
+func notifyUsers(userIDs []uuid.UUID, notificationType, content string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	notifications := make([]models.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notifications = append(notifications, models.Notification{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Type:      notificationType,
+			Content:   content,
+			Status:    "unread",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+	}
+	return utils.DB.CreateInBatches(&notifications, 500).Error
+}
+
 // CreateNotification creates a new notification using the user ID from the token.
 func CreateNotification(c *gin.Context) {
 	claims, exists := c.Get("claims")
@@ -57,11 +95,16 @@ func CreateNotification(c *gin.Context) {
 }
 
 // GetNotificationByID returns a notification by its ID.
+// Notifications belonging to other users are reported as not found.
 func GetNotificationByID(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
 	id := c.Param("id")
 
 	var notification models.Notification
-	if err := utils.DB.Where("id = ?", id).First(&notification).Error; err != nil {
+	if err := utils.DB.Scopes(notificationPolicy(userClaims)).Where("id = ?", id).First(&notification).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
 		} else {
@@ -74,16 +117,11 @@ func GetNotificationByID(c *gin.Context) {
 }
 
 // ListNotificationsByUser lists all notifications for the authenticated user.
-// Admins can view notifications for any user by providing a "user_id" query parameter.
+// Users with notification:manage can view notifications for any user by
+// providing a "user_id" query parameter.
 func ListNotificationsByUser(c *gin.Context) {
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
+	userClaims, ok := currentClaims(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 		return
 	}
 
@@ -91,41 +129,35 @@ func ListNotificationsByUser(c *gin.Context) {
 	userID := c.Query("user_id")
 	if userID == "" {
 		userID = userClaims.UserID
-	} else {
-		// If a user_id is provided, and the caller is not admin, deny access if not matching.
-		if userClaims.Role != "admin" && userClaims.UserID != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own notifications"})
-			return
-		}
+	}
+
+	page, ok := newPage(c, "notifications", newestFirst)
+	if !ok {
+		return
 	}
 
 	var notifications []models.Notification
-	if err := utils.DB.Where("user_id = ?", userID).Find(&notifications).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+	query := utils.DB.Model(&models.Notification{}).Scopes(notificationPolicy(userClaims)).Where("user_id = ?", userID)
+	result, ok := findPage(c, page, query, &notifications, "Failed to fetch notifications")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+	c.JSON(http.StatusOK, result.Apply(gin.H{"notifications": notifications}))
 }
 
 // UpdateNotificationByID updates a notification by its ID.
-// Only the owner (from token) or an admin can update.
+// Only the owner or a user with notification:manage can update.
 func UpdateNotificationByID(c *gin.Context) {
 	id := c.Param("id")
 
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
+	userClaims, ok := currentClaims(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 		return
 	}
 
 	var notification models.Notification
-	if err := utils.DB.Where("id = ?", id).First(&notification).Error; err != nil {
+	if err := utils.DB.Scopes(notificationPolicy(userClaims)).Where("id = ?", id).First(&notification).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
 		} else {
@@ -134,12 +166,6 @@ func UpdateNotificationByID(c *gin.Context) {
 		return
 	}
 
-	// Only the owner or admin can update the notification.
-	if userClaims.Role != "admin" && notification.UserID.String() != userClaims.UserID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
-		return
-	}
-
 	var input struct {
 		Type    string `json:"type,omitempty"`
 		Content string `json:"content,omitempty"`
@@ -151,6 +177,7 @@ func UpdateNotificationByID(c *gin.Context) {
 		return
 	}
 
+	before := notification
 	if input.Type != "" {
 		notification.Type = input.Type
 	}
@@ -170,6 +197,7 @@ func UpdateNotificationByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
 		return
 	}
+	recordAuditChange(c, "notification", notification.ID.String(), before, notification)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":      "Notification updated successfully",
@@ -178,16 +206,10 @@ func UpdateNotificationByID(c *gin.Context) {
 }
 
 // BulkDeleteNotifications deletes multiple notifications by their IDs.
-// Only the owner or an admin can perform bulk deletion.
+// Nothing is deleted unless every notification is visible to the caller.
 func BulkDeleteNotifications(c *gin.Context) {
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
+	userClaims, ok := currentClaims(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 		return
 	}
 
@@ -199,34 +221,18 @@ func BulkDeleteNotifications(c *gin.Context) {
 		return
 	}
 
-	// For non-admin users, ensure all notifications belong to the authenticated user.
-	if userClaims.Role != "admin" {
-		for _, id := range input.IDs {
-			var notif models.Notification
-			if err := utils.DB.Where("id = ?", id).First(&notif).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found: " + id})
-				} else {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification: " + id})
-				}
-				return
-			}
-			if notif.UserID.String() != userClaims.UserID {
-				c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied for notification: " + id})
-				return
-			}
-		}
-		// Delete notifications that belong to the user.
-		if err := utils.DB.Where("id IN ? AND user_id = ?", input.IDs, userClaims.UserID).Delete(&models.Notification{}).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notifications"})
-			return
-		}
-	} else {
-		// Admin can delete any notifications.
-		if err := utils.DB.Where("id IN ?", input.IDs).Delete(&models.Notification{}).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notifications"})
-			return
-		}
+	var deleted []models.Notification
+	missing, err := deleteVisible(notificationPolicy(userClaims), &deleted, input.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notifications"})
+		return
+	}
+	if missing != "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found: " + missing})
+		return
+	}
+	for _, notification := range deleted {
+		recordAuditChange(c, "notification", notification.ID.String(), notification, nil)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Notifications deleted successfully"})
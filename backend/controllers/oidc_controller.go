@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// oidcStateTTL is how long a user has to finish signing in at the provider.
+const oidcStateTTL = 10 * time.Minute
+
+var errOIDCEmailNotVerified = errors.New("provider did not verify the email address")
+
+// ListOIDCProviders returns the identity providers users can sign in with.
+func ListOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": utils.OIDCProviderNames()})
+}
+
+// StartOIDCLogin begins an authorization code flow with PKCE. The client
+// sends the browser to authorization_url; the provider redirects back to the
+// configured redirect URL with a code and the state.
+func StartOIDCLogin(c *gin.Context) {
+	provider, ok := utils.OIDCProviderByName(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+		return
+	}
+
+	state, stateHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+	nonce, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+	verifier, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+
+	authURL, err := provider.AuthorizationURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Printf("OIDC provider %s unavailable: %v", provider.Name(), err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return
+	}
+
+	loginState := models.OIDCLoginState{
+		StateHash:    stateHash,
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}
+	if err := utils.DB.Create(&loginState).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"authorization_url": authURL,
+		"state":             state,
+	})
+}
+
+// CompleteOIDCLogin redeems the code the provider redirected back with and
+// signs the user in, linking or creating their account by verified email.
+func CompleteOIDCLogin(c *gin.Context) {
+	provider, ok := utils.OIDCProviderByName(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+		return
+	}
+
+	var input struct {
+		Code  string `json:"code" binding:"required"`
+		State string `json:"state" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Each state can be redeemed once, by the provider it was issued for
+	var loginState models.OIDCLoginState
+	result := utils.DB.Clauses(clause.Returning{}).
+		Where("state_hash = ? AND provider = ?", utils.HashToken(input.State), provider.Name()).
+		Delete(&loginState)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete sign-in"})
+		return
+	}
+	if result.RowsAffected == 0 || time.Now().After(loginState.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired sign-in state"})
+		return
+	}
+
+	rawIDToken, err := provider.Exchange(c.Request.Context(), input.Code, loginState.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC code exchange with %s failed: %v", provider.Name(), err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to sign in with identity provider"})
+		return
+	}
+	identity, err := provider.VerifyIDToken(c.Request.Context(), rawIDToken, loginState.Nonce)
+	if err != nil {
+		log.Printf("OIDC ID token from %s rejected: %v", provider.Name(), err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to sign in with identity provider"})
+		return
+	}
+
+	user, created, err := linkExternalIdentity(provider.Name(), identity)
+	if errors.Is(err, errOIDCEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your identity provider has not verified your email address"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete sign-in"})
+		return
+	}
+
+	if !activeAccount(c, user) {
+		return
+	}
+	if user.TOTPEnabled {
+		loginChallengeResponse(c, user)
+		return
+	}
+
+	session, err := issueSession(user, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	response := session.response()
+	response["new_user"] = created
+	c.JSON(http.StatusOK, response)
+}
+
+// linkExternalIdentity finds the user behind a provider identity. The first
+// sign-in links the identity to the account with the same verified email, or
+// creates a donor account if there is none. It reports whether a user was
+// created.
+func linkExternalIdentity(provider string, identity utils.OIDCIdentity) (models.User, bool, error) {
+	var user models.User
+	var created bool
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var link models.ExternalIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, identity.Subject).First(&link).Error
+		if err == nil {
+			if err := tx.Model(&link).Update("last_login_at", now).Error; err != nil {
+				return err
+			}
+			return tx.Where("id = ?", link.UserID).First(&user).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// Only an address the provider has verified may claim an account
+		if identity.Email == "" || !identity.EmailVerified {
+			return errOIDCEmailNotVerified
+		}
+
+		err = tx.Where("email = ?", identity.Email).First(&user).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			name := identity.Name
+			if name == "" {
+				name = identity.Email
+			}
+			user = models.User{
+				ID:              uuid.New(),
+				Email:           identity.Email,
+				PasswordHash:    "", // Signs in through the provider
+				FullName:        name,
+				Role:            utils.RoleDonor,
+				Status:          models.UserStatusActive,
+				EmailVerified:   true,
+				EmailVerifiedAt: &now,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			created = true
+		case err != nil:
+			return err
+		case !user.EmailVerified:
+			// Nobody proved control of this address before, so whoever set
+			// the password may not own it: drop the password and its sessions
+			if err := tx.Model(&user).Updates(map[string]interface{}{
+				"email_verified":    true,
+				"email_verified_at": now,
+				"password_hash":     "",
+				"token_version":     gorm.Expr("token_version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+			if err := revokeUserRefreshTokens(tx, user.ID); err != nil {
+				return err
+			}
+			if err := tx.Where("id = ?", user.ID).First(&user).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&models.ExternalIdentity{
+			UserID:      user.ID,
+			Provider:    provider,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LastLoginAt: &now,
+		}).Error
+	})
+	return user, created, err
+}
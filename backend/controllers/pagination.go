@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/filter"
+	"backend/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// newestFirst is the default order of list endpoints.
+var newestFirst = pagination.Key{Column: "created_at", Desc: true}
+
+// newPage reads the paging parameters of a list request, answering 400 when
+// they are invalid.
+func newPage(c *gin.Context, resource string, keys ...pagination.Key) (*pagination.Page, bool) {
+	page, err := pagination.FromRequest(c, resource, keys...)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return page, true
+}
+
+// findPage loads one page of query into dest. On failure it answers with
+// failure as the error message, or 400 for a bad cursor.
+func findPage[T any](c *gin.Context, page *pagination.Page, query *gorm.DB, dest *[]T, failure string) (pagination.Result, bool) {
+	result, err := pagination.Find(page, query, dest)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return result, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return result, false
+	}
+	return result, true
+}
+
+// applyFilter narrows query by the filter query parameter, answering 400
+// when it names a field or operator fields does not allow.
+func applyFilter(c *gin.Context, query *gorm.DB, fields filter.Fields) (*gorm.DB, bool) {
+	scope, err := fields.Filter(c.Query("filter"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return query.Scopes(scope), true
+}
+
+// listSort reads the sort query parameter, e.g. "-created_at,title". The
+// older sort_by and order parameters still work for a single field. Without
+// either the newest rows come first.
+func listSort(c *gin.Context, fields filter.Fields) ([]pagination.Key, bool) {
+	expr := c.Query("sort")
+	if expr == "" && c.Query("sort_by") != "" {
+		expr = c.Query("sort_by")
+		// Default to ascending order if order is not specified or invalid
+		if c.Query("order") == "desc" {
+			expr = "-" + expr
+		}
+	}
+	if expr == "" {
+		return []pagination.Key{newestFirst}, true
+	}
+
+	keys, err := fields.Sort(expr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return keys, true
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// passwordResetTokenTTL is how long an emailed reset link stays valid.
+const passwordResetTokenTTL = time.Hour
+
+var errInvalidResetToken = errors.New("invalid or expired reset token")
+
+// ForgotPassword emails a one-time password reset link to the given address.
+// It always responds with the same message so it cannot be used to probe
+// which emails are registered.
+func ForgotPassword(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "If an account exists for that email, a password reset link has been sent"}
+
+	var user models.User
+	if err := utils.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("error looking up user for password reset: %v", err)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	rawToken, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate reset token"})
+		return
+	}
+
+	// Only the most recent link should work, so drop any outstanding ones.
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).
+			Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(passwordResetTokenTTL),
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset token"})
+		return
+	}
+
+	sendActionEmail(user.Email, user.FullName,
+		"Reset your Impacta password",
+		"We received a request to reset the password for your Impacta account. This link expires in one hour and can only be used once.",
+		"Choose a New Password",
+		utils.FrontendURL("/reset-password?token="+rawToken))
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword redeems a reset token, sets the new password and invalidates
+// every JWT previously issued to the user.
+func ResetPassword(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=8"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		// Claim the token atomically so concurrent requests cannot both redeem it.
+		now := time.Now()
+		var resetToken models.PasswordResetToken
+		if err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", utils.HashToken(input.Token), now).
+			First(&resetToken).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errInvalidResetToken
+			}
+			return err
+		}
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvalidResetToken
+		}
+
+		// Redeeming an emailed link also proves control of the address.
+		if err := tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).Updates(map[string]interface{}{
+			"password_hash":     string(hashed),
+			"token_version":     gorm.Expr("token_version + 1"),
+			"email_verified":    true,
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", now),
+		}).Error; err != nil {
+			return err
+		}
+		return revokeUserRefreshTokens(tx, resetToken.UserID)
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
@@ -49,6 +49,7 @@ func CreatePaymentTransaction(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment transaction"})
 		return
 	}
+	recordAuditChange(c, "payment_transaction", pt.ID.String(), nil, pt)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":             "Payment transaction created successfully",
@@ -56,11 +57,16 @@ func CreatePaymentTransaction(c *gin.Context) {
 	})
 }
 
-// GetPaymentTransactionByID retrieves a payment transaction by its ID.
+// GetPaymentTransactionByID retrieves a payment transaction by its ID. Only the
+// donor, the campaign creator and users with payment:read can see it; others get a 404.
 func GetPaymentTransactionByID(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
 	id := c.Param("id")
 	var pt models.PaymentTransaction
-	if err := utils.DB.Where("id = ?", id).First(&pt).Error; err != nil {
+	if err := utils.DB.Scopes(paymentTransactionPolicy(userClaims)).Where("id = ?", id).First(&pt).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Payment transaction not found"})
 		} else {
@@ -71,41 +77,43 @@ func GetPaymentTransactionByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"payment_transaction": pt})
 }
 
-// ListPaymentTransactions lists all payment transactions, with optional filtering by donation_id.
+// ListPaymentTransactions lists the payment transactions visible to the caller, with optional filtering by donation_id.
 func ListPaymentTransactions(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
 	donationID := c.Query("donation_id")
 
-	query := utils.DB.Model(&models.PaymentTransaction{})
+	page, ok := newPage(c, "payment_transactions", newestFirst)
+	if !ok {
+		return
+	}
+
+	query := utils.DB.Model(&models.PaymentTransaction{}).Scopes(paymentTransactionPolicy(userClaims))
 	if donationID != "" {
 		query = query.Where("donation_id = ?", donationID)
 	}
 
 	var pts []models.PaymentTransaction
-	if err := query.Find(&pts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payment transactions"})
+	result, ok := findPage(c, page, query, &pts, "Failed to fetch payment transactions")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"payment_transactions": pts})
+	c.JSON(http.StatusOK, result.Apply(gin.H{"payment_transactions": pts}))
 }
 
 // UpdatePaymentTransaction updates a payment transaction by its ID.
-// This endpoint is restricted to admins.
+// The route requires the payment:manage permission.
 func UpdatePaymentTransaction(c *gin.Context) {
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok || userClaims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	userClaims, ok := currentClaims(c)
+	if !ok {
 		return
 	}
-
 	id := c.Param("id")
 	var pt models.PaymentTransaction
-	if err := utils.DB.Where("id = ?", id).First(&pt).Error; err != nil {
+	if err := utils.DB.Scopes(paymentTransactionPolicy(userClaims)).Where("id = ?", id).First(&pt).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Payment transaction not found"})
 		} else {
@@ -126,6 +134,7 @@ func UpdatePaymentTransaction(c *gin.Context) {
 		return
 	}
 
+	before := pt
 	if input.Gateway != "" {
 		pt.Gateway = input.Gateway
 	}
@@ -147,6 +156,7 @@ func UpdatePaymentTransaction(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment transaction"})
 		return
 	}
+	recordAuditChange(c, "payment_transaction", pt.ID.String(), before, pt)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":             "Payment transaction updated successfully",
@@ -155,16 +165,10 @@ func UpdatePaymentTransaction(c *gin.Context) {
 }
 
 // BulkDeletePaymentTransactions deletes multiple payment transactions by their IDs.
-// This operation is restricted to admins.
+// The route requires the payment:manage permission.
 func BulkDeletePaymentTransactions(c *gin.Context) {
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok || userClaims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	userClaims, ok := currentClaims(c)
+	if !ok {
 		return
 	}
 
@@ -176,10 +180,19 @@ func BulkDeletePaymentTransactions(c *gin.Context) {
 		return
 	}
 
-	if err := utils.DB.Where("id IN ?", input.IDs).Delete(&models.PaymentTransaction{}).Error; err != nil {
+	var deleted []models.PaymentTransaction
+	missing, err := deleteVisible(paymentTransactionPolicy(userClaims), &deleted, input.IDs)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete payment transactions"})
 		return
 	}
+	if missing != "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment transaction not found: " + missing})
+		return
+	}
+	for _, pt := range deleted {
+		recordAuditChange(c, "payment_transaction", pt.ID.String(), pt, nil)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Payment transactions deleted successfully"})
 }
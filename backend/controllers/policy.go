@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// The policies below narrow a query on one resource type to the records the
+// caller is allowed to see. Handlers apply them as scopes to every get, list,
+// update and delete, so records outside the scope look exactly like records
+// that do not exist.
+
+// notificationPolicy: the recipient, or anyone with notification:manage.
+func notificationPolicy(claims *utils.Claims) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if utils.HasPermission(claims.Role, utils.PermNotificationManage) {
+			return db
+		}
+		return db.Where("user_id = ?", claims.UserID)
+	}
+}
+
+// supportTicketPolicy: the ticket owner, or anyone with support:manage.
+func supportTicketPolicy(claims *utils.Claims) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if utils.HasPermission(claims.Role, utils.PermSupportManage) {
+			return db
+		}
+		return db.Where("user_id = ?", claims.UserID)
+	}
+}
+
+// withdrawalPolicy: the campaign's owners and finance team members, or anyone
+// with withdrawal:read.
+func withdrawalPolicy(claims *utils.Claims) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if utils.HasPermission(claims.Role, utils.PermWithdrawalRead) {
+			return db
+		}
+		return db.Where("campaign_id IN (?)", campaignsWith(claims.UserID, models.CampaignCanFinance))
+	}
+}
+
+// paymentTransactionPolicy: the donor, the creator of the campaign donated
+// to, or anyone with payment:read.
+func paymentTransactionPolicy(claims *utils.Claims) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if utils.HasPermission(claims.Role, utils.PermPaymentRead) {
+			return db
+		}
+		return db.Where(`donation_id IN (
+			SELECT donations.id FROM donations
+			JOIN campaigns ON campaigns.id = donations.campaign_id
+			WHERE donations.donor_id = ? OR campaigns.creator_id = ?)`, claims.UserID, claims.UserID)
+	}
+}
+
+// apiKeyPolicy: only the owner of a key can see or revoke it.
+func apiKeyPolicy(claims *utils.Claims) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", claims.UserID)
+	}
+}
+
+// currentClaims returns the authenticated user's claims, responding with 401
+// when they are missing.
+func currentClaims(c *gin.Context) (*utils.Claims, bool) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+	userClaims, ok := claims.(*utils.Claims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		return nil, false
+	}
+	return userClaims, true
+}
+
+// deleteVisible deletes the records with the given IDs, but only if every one
+// of them is visible under the policy. Otherwise nothing is deleted and the
+// first ID that could not be found is returned. When model points to a slice
+// it is filled with the deleted records.
+func deleteVisible(scope func(*gorm.DB) *gorm.DB, model interface{}, ids []string) (string, error) {
+	parsed := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return id, nil
+		}
+		parsed = append(parsed, uid)
+	}
+
+	var missing string
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		var found []uuid.UUID
+		if err := tx.Model(model).Scopes(scope).Where("id IN ?", parsed).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		visible := make(map[uuid.UUID]bool, len(found))
+		for _, id := range found {
+			visible[id] = true
+		}
+		for i, id := range parsed {
+			if !visible[id] {
+				missing = ids[i]
+				return nil
+			}
+		}
+		return tx.Clauses(clause.Returning{}).Where("id IN ?", parsed).Delete(model).Error
+	})
+	return missing, err
+}
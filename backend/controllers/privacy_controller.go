@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errAlreadyErased = errors.New("user already erased")
+
+// Placeholders written over personal data on erasure.
+const (
+	erasedName    = "Erased user"
+	erasedContent = "[removed at the user's request]"
+)
+
+// ExportUserData returns an archive of the personal data held about the
+// logged-in user: their profile, campaigns, campaign team memberships,
+// donations, reward claims, comments, support tickets, notifications and
+// linked sign-in providers. Deleted rows that have not been purged yet are
+// included.
+func ExportUserData(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", userClaims.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var (
+		campaigns     []models.Campaign
+		memberships   []models.CampaignMember
+		donations     []models.Donation
+		rewardClaims  []models.RewardClaim
+		comments      []models.Comment
+		tickets       []models.SupportTicket
+		notifications []models.Notification
+		identities    []models.ExternalIdentity
+	)
+	queries := []struct {
+		dest  interface{}
+		query *gorm.DB
+	}{
+		{&campaigns, utils.DB.Unscoped().Where("creator_id = ?", user.ID)},
+		{&memberships, utils.DB.Where("user_id = ?", user.ID)},
+		{&donations, utils.DB.Where("donor_id = ?", user.ID)},
+		{&rewardClaims, utils.DB.Where("donor_id = ?", user.ID)},
+		{&comments, utils.DB.Unscoped().Where("user_id = ?", user.ID)},
+		{&tickets, utils.DB.Unscoped().Where("user_id = ?", user.ID)},
+		{&notifications, utils.DB.Unscoped().Where("user_id = ?", user.ID)},
+		{&identities, utils.DB.Where("user_id = ?", user.ID)},
+	}
+	for _, q := range queries {
+		if err := q.query.Order("created_at").Find(q.dest).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export user data"})
+			return
+		}
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="impacta-export-`+user.ID.String()+`.json"`)
+	c.JSON(http.StatusOK, gin.H{
+		"exported_at":       time.Now(),
+		"profile":           user,
+		"campaigns":         campaigns,
+		"campaign_teams":    memberships,
+		"donations":         donations,
+		"reward_claims":     rewardClaims,
+		"comments":          comments,
+		"support_tickets":   tickets,
+		"notifications":     notifications,
+		"linked_identities": identities,
+	})
+}
+
+// EraseUser erases another user's personal data on their behalf, e.g. for a
+// request received by email.
+func EraseUser(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", c.Param("id")).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+	if user.ID.String() == userClaims.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use DELETE /user to erase your own account"})
+		return
+	}
+	// Only admins can act on other admins
+	if user.Role == utils.RoleAdmin && userClaims.Role != utils.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	adminID, _ := uuid.Parse(userClaims.UserID)
+	eraseUserResponse(c, user, &adminID)
+}
+
+// eraseUserResponse erases the user and writes the outcome.
+func eraseUserResponse(c *gin.Context, user models.User, erasedBy *uuid.UUID) {
+	err := eraseUser(user, erasedBy)
+	switch {
+	case errors.Is(err, errAlreadyErased):
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already erased"})
+		return
+	case errors.Is(err, errLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last admin"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to erase user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User erased successfully"})
+}
+
+// eraseUser removes a user's personal data while keeping every record that
+// accounting needs. The user row stays, so donations, campaigns and payment
+// transactions still reference it, but its email, name and credentials are
+// replaced. Donations become anonymous and lose their message, comments and
+// ticket contents are blanked, and notifications, sessions, API keys and
+// linked identities are deleted.
+func eraseUser(user models.User, erasedBy *uuid.UUID) error {
+	return utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", user.ID).First(&user).Error; err != nil {
+			return err
+		}
+		if user.Status == models.UserStatusErased {
+			return errAlreadyErased
+		}
+		if user.Role == utils.RoleAdmin {
+			var admins int64
+			if err := tx.Model(&models.User{}).
+				Where("role = ? AND status = ? AND id <> ?", utils.RoleAdmin, models.UserStatusActive, user.ID).
+				Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins == 0 {
+				return errLastAdmin
+			}
+		}
+
+		now := time.Now()
+		oldEmail := user.Email
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"email":               "erased-" + user.ID.String() + "@erased.invalid",
+			"full_name":           erasedName,
+			"password_hash":       "",
+			"status":              models.UserStatusErased,
+			"status_reason":       "",
+			"status_changed_at":   now,
+			"status_changed_by":   erasedBy,
+			"erased_at":           now,
+			"token_version":       gorm.Expr("token_version + 1"),
+			"email_verified":      false,
+			"email_verified_at":   nil,
+			"totp_secret":         "",
+			"totp_enabled":        false,
+			"totp_enabled_at":     nil,
+			"totp_recovery_codes": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Donation{}).Where("donor_id = ?", user.ID).
+			Updates(map[string]interface{}{"is_anonymous": true, "message": ""}).Error; err != nil {
+			return err
+		}
+		// Reward claims keep their status for the creator's records, without
+		// the contact and shipping details
+		if err := tx.Model(&models.RewardClaim{}).Where("donor_id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"contact_email": "", "contact_phone": "", "shipping_name": "", "address_line1": "",
+				"address_line2": "", "city": "", "region": "", "postal_code": "", "country": "",
+			}).Error; err != nil {
+			return err
+		}
+		// Deleted rows are erased too, rather than left until they are purged
+		if err := tx.Unscoped().Model(&models.Comment{}).Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"content": erasedContent, "status": "deleted"}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.SupportTicket{}).Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"query": erasedContent, "answer": ""}).Error; err != nil {
+			return err
+		}
+
+		if err := revokeUserRefreshTokens(tx, user.ID); err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&models.Notification{},
+			&models.PasswordResetToken{},
+			&models.APIKey{},
+			&models.ExternalIdentity{},
+			&models.CampaignFollower{},
+			&models.CampaignMember{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("LOWER(email) = LOWER(?)", oldEmail).Delete(&models.CampaignInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("key = ?", accountThrottle().key(oldEmail)).Delete(&models.LoginThrottle{}).Error
+	})
+}
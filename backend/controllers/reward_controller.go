@@ -0,0 +1,522 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errRewardSoldOut     = errors.New("reward sold out")
+	errRewardClaimStale  = errors.New("reward claim changed meanwhile")
+	errRewardLimitTooLow = errors.New("quantity limit below rewards claimed")
+)
+
+// rewardShippingInput is how a donor who picked a reward can be reached and
+// where it should be sent.
+type rewardShippingInput struct {
+	Name         string `json:"name"`
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2"`
+	City         string `json:"city"`
+	Region       string `json:"region"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+	Phone        string `json:"phone"`
+}
+
+// complete reports whether the address has everything a parcel needs.
+func (s rewardShippingInput) complete() bool {
+	for _, field := range []string{s.Name, s.AddressLine1, s.City, s.PostalCode, s.Country} {
+		if strings.TrimSpace(field) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ListRewardTiers lists the rewards of a campaign from the cheapest up.
+// QuantityLimit minus QuantityClaimed is what is left of a limited reward.
+func ListRewardTiers(c *gin.Context) {
+	campaign, ok := visibleCampaign(c)
+	if !ok {
+		return
+	}
+	tiers := []models.RewardTier{}
+	if err := utils.DB.Where("campaign_id = ?", campaign.ID).Order("minimum_amount").Find(&tiers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rewards"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rewards": tiers})
+}
+
+// CreateRewardTier adds a reward to a campaign. Only the creator or a
+// moderator can add rewards.
+func CreateRewardTier(c *gin.Context) {
+	_, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title             string     `json:"title" binding:"required"`
+		Description       string     `json:"description"`
+		MinimumAmount     float64    `json:"minimum_amount" binding:"required,gt=0"`
+		QuantityLimit     *int       `json:"quantity_limit"` // Omitted or 0 for unlimited
+		EstimatedDelivery *time.Time `json:"estimated_delivery"`
+		RequiresShipping  bool       `json:"requires_shipping"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required"})
+		return
+	}
+	limit, ok := rewardQuantityLimit(c, input.QuantityLimit)
+	if !ok {
+		return
+	}
+
+	tier := models.RewardTier{
+		ID:                uuid.New(),
+		CampaignID:        campaign.ID,
+		Title:             input.Title,
+		Description:       input.Description,
+		MinimumAmount:     input.MinimumAmount,
+		QuantityLimit:     limit,
+		EstimatedDelivery: input.EstimatedDelivery,
+		RequiresShipping:  input.RequiresShipping,
+	}
+	if err := utils.DB.Create(&tier).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reward"})
+		return
+	}
+	recordAuditChange(c, "reward_tier", tier.ID.String(), nil, tier)
+	c.JSON(http.StatusCreated, gin.H{"message": "Reward created", "reward": tier})
+}
+
+// UpdateRewardTier edits a reward. Its quantity limit cannot go below the
+// rewards already claimed.
+func UpdateRewardTier(c *gin.Context) {
+	_, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+	tier, ok := rewardTier(c, campaign)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title             string     `json:"title"`
+		Description       *string    `json:"description"`
+		MinimumAmount     *float64   `json:"minimum_amount"`
+		QuantityLimit     *int       `json:"quantity_limit"` // 0 removes the limit
+		EstimatedDelivery *time.Time `json:"estimated_delivery"`
+		RequiresShipping  *bool      `json:"requires_shipping"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := tier
+	updates := map[string]interface{}{}
+	if title := strings.TrimSpace(input.Title); title != "" {
+		updates["title"] = title
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if input.MinimumAmount != nil {
+		if *input.MinimumAmount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Minimum amount must be greater than 0"})
+			return
+		}
+		updates["minimum_amount"] = *input.MinimumAmount
+	}
+	if input.EstimatedDelivery != nil {
+		updates["estimated_delivery"] = *input.EstimatedDelivery
+	}
+	if input.RequiresShipping != nil {
+		updates["requires_shipping"] = *input.RequiresShipping
+	}
+	var limit *int
+	if input.QuantityLimit != nil {
+		if limit, ok = rewardQuantityLimit(c, input.QuantityLimit); !ok {
+			return
+		}
+		updates["quantity_limit"] = limit
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Reward updated", "reward": tier})
+		return
+	}
+
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&tier)
+		// Checked in the update itself so a donation claiming the reward
+		// meanwhile cannot push it over the new limit
+		if limit != nil {
+			query = query.Where("quantity_claimed <= ?", *limit)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRewardLimitTooLow
+		}
+		return tx.First(&tier, "id = ?", tier.ID).Error
+	})
+	if errors.Is(err, errRewardLimitTooLow) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Quantity limit is below the rewards already claimed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reward"})
+		return
+	}
+	recordAuditChange(c, "reward_tier", tier.ID.String(), before, tier)
+	c.JSON(http.StatusOK, gin.H{"message": "Reward updated", "reward": tier})
+}
+
+// DeleteRewardTier removes a reward nobody has claimed.
+func DeleteRewardTier(c *gin.Context) {
+	_, campaign, ok := managedCampaign(c)
+	if !ok {
+		return
+	}
+	tier, ok := rewardTier(c, campaign)
+	if !ok {
+		return
+	}
+
+	var claims int64
+	if err := utils.DB.Model(&models.RewardClaim{}).Where("reward_tier_id = ?", tier.ID).Count(&claims).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reward"})
+		return
+	}
+	if claims > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A reward donors have claimed cannot be deleted"})
+		return
+	}
+	if err := utils.DB.Delete(&tier).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reward"})
+		return
+	}
+	recordAuditChange(c, "reward_tier", tier.ID.String(), tier, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Reward deleted"})
+}
+
+// ListRewardClaims is the creator's fulfilment list: the rewards donors
+// claimed, newest first, optionally narrowed by status and reward_tier_id.
+func ListRewardClaims(c *gin.Context) {
+	_, campaign, ok := fulfilmentCampaign(c)
+	if !ok {
+		return
+	}
+	page, ok := newPage(c, "reward-claims", newestFirst)
+	if !ok {
+		return
+	}
+	query, ok := rewardClaimsQuery(c, campaign)
+	if !ok {
+		return
+	}
+
+	var claims []models.RewardClaim
+	result, ok := findPage(c, page, query.Preload("RewardTier"), &claims, "Failed to fetch reward claims")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, result.Apply(gin.H{"reward_claims": claims}))
+}
+
+// ExportRewardClaims writes the fulfilment list as CSV, oldest first, with
+// the same filters as ListRewardClaims.
+func ExportRewardClaims(c *gin.Context) {
+	_, campaign, ok := fulfilmentCampaign(c)
+	if !ok {
+		return
+	}
+	query, ok := rewardClaimsQuery(c, campaign)
+	if !ok {
+		return
+	}
+
+	var claims []models.RewardClaim
+	if err := query.Preload("RewardTier").Preload("Donation.Donor").
+		Order("created_at, id").Find(&claims).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reward claims"})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="rewards-`+campaign.ID.String()+`.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"claim_id", "claimed_at", "reward", "status", "tracking_number", "donor_name", "contact_email",
+		"contact_phone", "shipping_name", "address_line1", "address_line2", "city", "region", "postal_code",
+		"country", "amount", "currency", "shipped_at", "delivered_at",
+	})
+	for _, claim := range claims {
+		w.Write([]string{
+			claim.ID.String(),
+			claim.CreatedAt.UTC().Format(time.RFC3339),
+			csvCell(claim.RewardTier.Title),
+			claim.Status,
+			csvCell(claim.TrackingNumber),
+			csvCell(claim.Donation.Donor.FullName),
+			csvCell(claim.ContactEmail),
+			csvCell(claim.ContactPhone),
+			csvCell(claim.ShippingName),
+			csvCell(claim.AddressLine1),
+			csvCell(claim.AddressLine2),
+			csvCell(claim.City),
+			csvCell(claim.Region),
+			csvCell(claim.PostalCode),
+			csvCell(claim.Country),
+			fmt.Sprintf("%.2f", claim.Donation.Amount),
+			claim.Donation.Currency,
+			csvTime(claim.ShippedAt),
+			csvTime(claim.DeliveredAt),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Failed to write reward export of campaign %s: %v", campaign.ID, err)
+	}
+}
+
+// UpdateRewardClaim moves a claim along its fulfilment and sets its tracking
+// number. The donor is notified when their reward ships, and cancelling a
+// claim puts the reward back in stock.
+func UpdateRewardClaim(c *gin.Context) {
+	_, campaign, ok := fulfilmentCampaign(c)
+	if !ok {
+		return
+	}
+	var claim models.RewardClaim
+	if err := utils.DB.Preload("RewardTier").Where("id = ? AND campaign_id = ?", c.Param("claim_id"), campaign.ID).
+		First(&claim).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward claim not found"})
+		return
+	}
+
+	var input struct {
+		Status         string  `json:"status"`
+		TrackingNumber *string `json:"tracking_number"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.TrackingNumber != nil {
+		updates["tracking_number"] = strings.TrimSpace(*input.TrackingNumber)
+	}
+	if input.Status != "" && input.Status != claim.Status {
+		if !models.CanTransitionRewardClaim(claim.Status, input.Status) {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot move a %s reward claim to %s", claim.Status, input.Status)})
+			return
+		}
+		updates["status"] = input.Status
+		switch input.Status {
+		case models.RewardClaimShipped:
+			updates["shipped_at"] = time.Now()
+		case models.RewardClaimDelivered:
+			updates["delivered_at"] = time.Now()
+		}
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Reward claim updated", "reward_claim": claim})
+		return
+	}
+
+	before := claim
+	previous := claim.Status
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		// Only apply the change to the status it was checked against
+		result := tx.Model(&claim).Where("status = ?", previous).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRewardClaimStale
+		}
+		if input.Status == models.RewardClaimCancelled {
+			if err := tx.Model(&models.RewardTier{}).Where("id = ?", claim.RewardTierID).
+				Update("quantity_claimed", gorm.Expr("quantity_claimed - 1")).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Preload("RewardTier").First(&claim, "id = ?", claim.ID).Error
+	})
+	if errors.Is(err, errRewardClaimStale) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The reward claim changed meanwhile, reload it and try again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reward claim"})
+		return
+	}
+	recordAuditChange(c, "reward_claim", claim.ID.String(), before, claim)
+
+	if previous != claim.Status && claim.Status == models.RewardClaimShipped {
+		content := fmt.Sprintf("Your reward \"%s\" from \"%s\" has shipped.", claim.RewardTier.Title, campaign.Title)
+		if claim.TrackingNumber != "" {
+			content += " Tracking number: " + claim.TrackingNumber
+		}
+		notifyUser(claim.DonorID, "reward_shipped", content)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reward claim updated", "reward_claim": claim})
+}
+
+// donationRewardTier checks the reward a donor picked with a donation: it
+// must belong to the campaign, the donation must be in the campaign's
+// currency and reach the reward's minimum, and rewards that ship need an
+// address. Stock is checked when the donation is saved.
+func donationRewardTier(c *gin.Context, campaign models.Campaign, tierID string, amount float64, currency string,
+	shipping rewardShippingInput) (*models.RewardTier, bool) {
+	var tier models.RewardTier
+	if err := utils.DB.Where("id = ? AND campaign_id = ?", tierID, campaign.ID).First(&tier).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reward not found for this campaign"})
+		return nil, false
+	}
+	if !strings.EqualFold(currency, campaign.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Donations with a reward must be in the campaign's currency"})
+		return nil, false
+	}
+	if amount < tier.MinimumAmount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("This reward needs a donation of at least %.2f %s", tier.MinimumAmount, campaign.Currency)})
+		return nil, false
+	}
+	if tier.RequiresShipping && !shipping.complete() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This reward ships, so a name, address line, city, postal code and country are required"})
+		return nil, false
+	}
+	return &tier, true
+}
+
+// claimReward takes one of the tier's rewards for the donation. The stock
+// check and the increment are one statement, so concurrent donations cannot
+// oversell a limited reward.
+func claimReward(tx *gorm.DB, donation models.Donation, tier models.RewardTier, email string,
+	shipping rewardShippingInput) (*models.RewardClaim, error) {
+	result := tx.Model(&models.RewardTier{}).
+		Where("id = ? AND (quantity_limit IS NULL OR quantity_claimed < quantity_limit)", tier.ID).
+		Update("quantity_claimed", gorm.Expr("quantity_claimed + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errRewardSoldOut
+	}
+
+	claim := models.RewardClaim{
+		ID:           uuid.New(),
+		DonationID:   donation.ID,
+		RewardTierID: tier.ID,
+		CampaignID:   donation.CampaignID,
+		DonorID:      donation.DonorID,
+		ContactEmail: email,
+		ContactPhone: strings.TrimSpace(shipping.Phone),
+		Status:       models.RewardClaimPending,
+	}
+	if tier.RequiresShipping {
+		claim.ShippingName = strings.TrimSpace(shipping.Name)
+		claim.AddressLine1 = strings.TrimSpace(shipping.AddressLine1)
+		claim.AddressLine2 = strings.TrimSpace(shipping.AddressLine2)
+		claim.City = strings.TrimSpace(shipping.City)
+		claim.Region = strings.TrimSpace(shipping.Region)
+		claim.PostalCode = strings.TrimSpace(shipping.PostalCode)
+		claim.Country = strings.TrimSpace(shipping.Country)
+	}
+	if err := tx.Omit(clause.Associations).Create(&claim).Error; err != nil {
+		return nil, err
+	}
+	claim.RewardTier = tier
+	return &claim, nil
+}
+
+// rewardTier loads the reward named in the route.
+func rewardTier(c *gin.Context, campaign models.Campaign) (models.RewardTier, bool) {
+	var tier models.RewardTier
+	if err := utils.DB.Where("id = ? AND campaign_id = ?", c.Param("reward_id"), campaign.ID).First(&tier).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+		return tier, false
+	}
+	return tier, true
+}
+
+// rewardQuantityLimit validates a quantity limit, where 0 means unlimited.
+func rewardQuantityLimit(c *gin.Context, limit *int) (*int, bool) {
+	if limit == nil || *limit == 0 {
+		return nil, true
+	}
+	if *limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity limit cannot be negative"})
+		return nil, false
+	}
+	return limit, true
+}
+
+// fulfilmentCampaign loads the campaign named in the route for work on its
+// reward claims. Claims hold donors' contact and shipping details, so only
+// owners and finance members of its team, or a moderator, get through.
+func fulfilmentCampaign(c *gin.Context) (*utils.Claims, models.Campaign, bool) {
+	return campaignForTeam(c, models.CampaignCanFinance)
+}
+
+// rewardClaimsQuery selects the campaign's claims, narrowed by the status
+// and reward_tier_id query parameters. It answers 400 for a malformed tier ID.
+func rewardClaimsQuery(c *gin.Context, campaign models.Campaign) (*gorm.DB, bool) {
+	query := utils.DB.Model(&models.RewardClaim{}).Where("campaign_id = ?", campaign.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if tierID := c.Query("reward_tier_id"); tierID != "" {
+		if _, err := uuid.Parse(tierID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reward_tier_id"})
+			return nil, false
+		}
+		query = query.Where("reward_tier_id = ?", tierID)
+	}
+	return query, true
+}
+
+// csvCell keeps a value a donor typed from being read as a formula by
+// spreadsheet software.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// csvTime formats an optional time for the CSV export.
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
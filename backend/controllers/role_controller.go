@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+var errLastAdmin = errors.New("cannot remove the last admin")
+
+// roleResponse renders a role with its granted permissions.
+func roleResponse(role models.Role) gin.H {
+	permissions := []string{}
+	if role.Name == utils.RoleAdmin {
+		for _, p := range utils.PermissionCatalog {
+			permissions = append(permissions, p.Name)
+		}
+	} else {
+		for _, rp := range role.Permissions {
+			permissions = append(permissions, rp.Permission)
+		}
+	}
+	return gin.H{
+		"name":        role.Name,
+		"description": role.Description,
+		"is_system":   role.IsSystem,
+		"permissions": permissions,
+	}
+}
+
+// validatePermissions returns the first name that is not in the catalog.
+func validatePermissions(permissions []string) (string, bool) {
+	for _, p := range permissions {
+		if !utils.IsPermission(p) {
+			return p, false
+		}
+	}
+	return "", true
+}
+
+// replaceRolePermissions swaps the grants of a role for the given set.
+func replaceRolePermissions(tx *gorm.DB, roleName string, permissions []string) error {
+	if err := tx.Where("role_name = ?", roleName).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, p := range permissions {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if err := tx.Create(&models.RolePermission{RoleName: roleName, Permission: p}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPermissions returns the catalog of permissions that can be granted.
+func ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"permissions": utils.PermissionCatalog})
+}
+
+// ListRoles returns every role with its permissions.
+func ListRoles(c *gin.Context) {
+	var roles []models.Role
+	if err := utils.DB.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(roles))
+	for _, role := range roles {
+		response = append(response, roleResponse(role))
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": response})
+}
+
+// CreateRole adds a custom role such as support_agent or finance.
+func CreateRole(c *gin.Context) {
+	var input struct {
+		Name        string   `json:"name" binding:"required"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !roleNamePattern.MatchString(input.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role names must be lowercase letters, digits or underscores"})
+		return
+	}
+	if unknown, ok := validatePermissions(input.Permissions); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permission: " + unknown})
+		return
+	}
+
+	var existing int64
+	utils.DB.Model(&models.Role{}).Where("name = ?", input.Name).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role already exists"})
+		return
+	}
+
+	role := models.Role{Name: input.Name, Description: input.Description}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissions(tx, role.Name, input.Permissions)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role"})
+		return
+	}
+	utils.InvalidateRolePermissions()
+
+	utils.DB.Preload("Permissions").First(&role, "name = ?", role.Name)
+	recordAuditChange(c, "role", role.Name, nil, roleResponse(role))
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"role":    roleResponse(role),
+	})
+}
+
+// UpdateRole changes a role's description and, when given, replaces its permissions.
+func UpdateRole(c *gin.Context) {
+	name := c.Param("name")
+	if name == utils.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The admin role always holds every permission"})
+		return
+	}
+
+	var input struct {
+		Description *string   `json:"description"`
+		Permissions *[]string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Permissions != nil {
+		if unknown, ok := validatePermissions(*input.Permissions); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permission: " + unknown})
+			return
+		}
+	}
+
+	var role models.Role
+	if err := utils.DB.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role"})
+		}
+		return
+	}
+	before := roleResponse(role)
+
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Description != nil {
+			if err := tx.Model(&role).Update("description", *input.Description).Error; err != nil {
+				return err
+			}
+		}
+		if input.Permissions != nil {
+			return replaceRolePermissions(tx, role.Name, *input.Permissions)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+	utils.InvalidateRolePermissions()
+
+	role.Permissions = nil
+	utils.DB.Preload("Permissions").First(&role, "name = ?", role.Name)
+	recordAuditChange(c, "role", role.Name, before, roleResponse(role))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"role":    roleResponse(role),
+	})
+}
+
+// DeleteRole removes a custom role that is no longer assigned to anyone.
+func DeleteRole(c *gin.Context) {
+	name := c.Param("name")
+
+	var role models.Role
+	if err := utils.DB.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role"})
+		}
+		return
+	}
+	if role.IsSystem {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in roles cannot be deleted"})
+		return
+	}
+
+	var assigned int64
+	utils.DB.Model(&models.User{}).Where("role = ?", name).Count(&assigned)
+	if assigned > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role is still assigned to users"})
+		return
+	}
+
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_name = ?", name).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
+		return
+	}
+	utils.InvalidateRolePermissions()
+	recordAuditChange(c, "role", role.Name, roleResponse(role), nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// AssignUserRole changes a user's role. The user's token version is bumped so
+// access tokens carrying the old role stop working; their refresh token
+// yields a new access token with the new role.
+func AssignUserRole(c *gin.Context) {
+	userID := c.Param("id")
+
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var role models.Role
+	if err := utils.DB.Where("name = ?", input.Role).First(&role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Role does not exist"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role"})
+		}
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+
+	previousRole := user.Role
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if user.Role == utils.RoleAdmin && role.Name != utils.RoleAdmin {
+			var admins int64
+			if err := tx.Model(&models.User{}).Where("role = ?", utils.RoleAdmin).Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins <= 1 {
+				return errLastAdmin
+			}
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"role":          role.Name,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error
+	})
+	if err == errLastAdmin {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last admin"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user role"})
+		return
+	}
+	recordAuditChange(c, "user", user.ID.String(), gin.H{"role": previousRole}, gin.H{"role": role.Name})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User role updated successfully",
+		"user": gin.H{
+			"id":    user.ID,
+			"email": user.Email,
+			"role":  role.Name,
+		},
+	})
+}
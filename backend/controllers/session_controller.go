@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// refreshTokenTTL is how long a refresh token can sit unused before the user
+// has to log in again. Every use rotates it, which resets the clock.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+var errRefreshTokenReused = errors.New("refresh token already used")
+
+// sessionTokens is the access/refresh token pair handed out on login.
+type sessionTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// response renders the token pair the way every auth endpoint returns it.
+// "token" is kept as the access token key for existing clients.
+func (s sessionTokens) response() gin.H {
+	return gin.H{
+		"token":         s.AccessToken,
+		"refresh_token": s.RefreshToken,
+		"expires_in":    int(utils.AccessTokenTTL.Seconds()),
+	}
+}
+
+// issueRefreshToken stores a new refresh token in the given family and returns
+// the record together with the raw token, which is never persisted.
+func issueRefreshToken(tx *gorm.DB, userID, familyID uuid.UUID, twoFactor bool) (models.RefreshToken, string, error) {
+	raw, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return models.RefreshToken{}, "", err
+	}
+	refreshToken := models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+		TwoFactor: twoFactor,
+	}
+	if err := tx.Create(&refreshToken).Error; err != nil {
+		return models.RefreshToken{}, "", err
+	}
+	return refreshToken, raw, nil
+}
+
+// accessTokenFor signs an access token for the user, recording whether the
+// session completed a second factor.
+func accessTokenFor(user models.User, twoFactor bool) (string, error) {
+	if twoFactor {
+		return utils.GenerateTwoFactorToken(user.ID.String(), user.Email, user.Role, user.TokenVersion)
+	}
+	return utils.GenerateToken(user.ID.String(), user.Email, user.Role, user.TokenVersion)
+}
+
+// issueSession generates an access token and starts a new refresh token family.
+func issueSession(user models.User, twoFactor bool) (sessionTokens, error) {
+	accessToken, err := accessTokenFor(user, twoFactor)
+	if err != nil {
+		return sessionTokens{}, err
+	}
+	_, refreshToken, err := issueRefreshToken(utils.DB, user.ID, uuid.New(), twoFactor)
+	if err != nil {
+		return sessionTokens{}, err
+	}
+	return sessionTokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// revokeRefreshTokenFamily revokes every live token descending from the same login.
+func revokeRefreshTokenFamily(tx *gorm.DB, familyID uuid.UUID) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// revokeUserRefreshTokens revokes every live refresh token belonging to the user.
+func revokeUserRefreshTokens(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a rotated
+// refresh token. Presenting an already-rotated token is treated as theft and
+// revokes the whole token family.
+func RefreshToken(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var stored models.RefreshToken
+	if err := utils.DB.Where("token_hash = ?", utils.HashToken(input.RefreshToken)).First(&stored).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	if stored.RevokedAt != nil {
+		rejectReusedRefreshToken(c, stored)
+		return
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired"})
+		return
+	}
+
+	// Fetch the latest user information so role changes take effect on refresh
+	var user models.User
+	if err := utils.DB.Where("id = ?", stored.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if !activeAccount(c, user) {
+		return
+	}
+
+	var rawRefreshToken string
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		next, raw, err := issueRefreshToken(tx, user.ID, stored.FamilyID, stored.TwoFactor)
+		if err != nil {
+			return err
+		}
+		// Only one request may rotate a given token; a loser of the race is a replay.
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", stored.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by_id": next.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenReused
+		}
+		rawRefreshToken = raw
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errRefreshTokenReused) {
+			rejectReusedRefreshToken(c, stored)
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate refresh token"})
+		}
+		return
+	}
+
+	accessToken, err := accessTokenFor(user, stored.TwoFactor)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessionTokens{AccessToken: accessToken, RefreshToken: rawRefreshToken}.response())
+}
+
+// rejectReusedRefreshToken revokes the family of a replayed token and responds 401.
+func rejectReusedRefreshToken(c *gin.Context, stored models.RefreshToken) {
+	log.Printf("refresh token reuse detected for user %s, revoking family %s", stored.UserID, stored.FamilyID)
+	if err := revokeRefreshTokenFamily(utils.DB, stored.FamilyID); err != nil {
+		log.Printf("error revoking refresh token family %s: %v", stored.FamilyID, err)
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has already been used; please log in again"})
+}
+
+// Logout revokes the given refresh token (and its family) for the authenticated user.
+func Logout(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userClaims, ok := claims.(*utils.Claims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		return
+	}
+
+	var input struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var stored models.RefreshToken
+	err := utils.DB.Where("token_hash = ? AND user_id = ?", utils.HashToken(input.RefreshToken), userClaims.UserID).
+		First(&stored).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch refresh token"})
+		return
+	}
+	// Logging out twice is harmless, so an unknown token is not an error.
+	if err == nil {
+		if err := revokeRefreshTokenFamily(utils.DB, stored.FamilyID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll revokes every refresh token of the authenticated user and
+// invalidates all of their outstanding access tokens.
+func LogoutAll(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userClaims, ok := claims.(*utils.Claims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		return
+	}
+
+	userID, err := uuid.Parse(userClaims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		return
+	}
+
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := revokeUserRefreshTokens(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).
+			Update("token_version", gorm.Expr("token_version + 1")).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out of all sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions successfully"})
+}
@@ -72,10 +72,16 @@ func CreateSupportTicket(c *gin.Context) {
 }
 
 // GetSupportTicketByID returns a support ticket by its ID.
+// Tickets belonging to other users are reported as not found.
 func GetSupportTicketByID(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
 	id := c.Param("id")
+
 	var ticket models.SupportTicket
-	if err := utils.DB.Where("id = ?", id).First(&ticket).Error; err != nil {
+	if err := utils.DB.Scopes(supportTicketPolicy(userClaims)).Where("id = ?", id).First(&ticket).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Support ticket not found"})
 		} else {
@@ -86,69 +92,46 @@ func GetSupportTicketByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"ticket": ticket})
 }
 
-// ListSupportTickets lists all support tickets for a given user.
-// Non-admin users can only view their own tickets. Admins can filter by user_id.
+// ListSupportTickets lists the support tickets visible to the caller: their
+// own, or every ticket for users with support:manage. An optional user_id
+// query parameter narrows the list further.
 func ListSupportTickets(c *gin.Context) {
-    claims, exists := c.Get("claims")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-        return
-    }
-    userClaims, ok := claims.(*utils.Claims)
-    if !ok {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-        return
-    }
-
-    var tickets []models.SupportTicket
-    var err error
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
 
-    if userClaims.Role == "admin" {
-        // Admins get all tickets
-        err = utils.DB.Find(&tickets).Error
-    } else {
-        // Non‑admins: allow optional ?user_id= but only their own
-        userID := c.Query("user_id")
-        if userID == "" {
-            userID = userClaims.UserID
-        } else if userClaims.UserID != userID {
-            c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own support tickets"})
-            return
-        }
+	page, ok := newPage(c, "support_tickets", newestFirst)
+	if !ok {
+		return
+	}
 
-        err = utils.DB.
-            Where("user_id = ?", userID).
-            Find(&tickets).
-            Error
-    }
+	query := utils.DB.Model(&models.SupportTicket{}).Scopes(supportTicketPolicy(userClaims))
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
 
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch support tickets"})
-        return
-    }
+	var tickets []models.SupportTicket
+	result, ok := findPage(c, page, query, &tickets, "Failed to fetch support tickets")
+	if !ok {
+		return
+	}
 
-    c.JSON(http.StatusOK, gin.H{"tickets": tickets})
+	c.JSON(http.StatusOK, result.Apply(gin.H{"tickets": tickets}))
 }
 
-
 // UpdateSupportTicketByID updates a support ticket by its ID.
-// Only the ticket owner or an admin can update.
+// Only the ticket owner or a user with support:manage can update.
 func UpdateSupportTicketByID(c *gin.Context) {
 	id := c.Param("id")
 
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
+	userClaims, ok := currentClaims(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 		return
 	}
 
 	var ticket models.SupportTicket
-	if err := utils.DB.Where("id = ?", id).First(&ticket).Error; err != nil {
+	if err := utils.DB.Scopes(supportTicketPolicy(userClaims)).Where("id = ?", id).First(&ticket).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Support ticket not found"})
 		} else {
@@ -157,12 +140,6 @@ func UpdateSupportTicketByID(c *gin.Context) {
 		return
 	}
 
-	// Only owner or admin can update
-	if userClaims.Role != "admin" && ticket.UserID.String() != userClaims.UserID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
-		return
-	}
-
 	var input struct {
 		Type     string `json:"type,omitempty"`
 		Priority string `json:"priority,omitempty"`
@@ -175,6 +152,7 @@ func UpdateSupportTicketByID(c *gin.Context) {
 		return
 	}
 
+	before := ticket
 	if input.Type != "" {
 		ticket.Type = input.Type
 	}
@@ -196,6 +174,7 @@ func UpdateSupportTicketByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update support ticket"})
 		return
 	}
+	recordAuditChange(c, "support_ticket", ticket.ID.String(), before, ticket)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Support ticket updated successfully",
@@ -204,16 +183,10 @@ func UpdateSupportTicketByID(c *gin.Context) {
 }
 
 // BulkDeleteSupportTickets deletes multiple support tickets by their IDs.
-// Only the ticket owner or an admin can perform this action.
+// Nothing is deleted unless every ticket is visible to the caller.
 func BulkDeleteSupportTickets(c *gin.Context) {
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
+	userClaims, ok := currentClaims(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 		return
 	}
 
@@ -225,34 +198,18 @@ func BulkDeleteSupportTickets(c *gin.Context) {
 		return
 	}
 
-	// For non-admin users, ensure all tickets belong to the authenticated user.
-	if userClaims.Role != "admin" {
-		for _, id := range input.IDs {
-			var ticket models.SupportTicket
-			if err := utils.DB.Where("id = ?", id).First(&ticket).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Support ticket not found: " + id})
-				} else {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch support ticket: " + id})
-				}
-				return
-			}
-			if ticket.UserID.String() != userClaims.UserID {
-				c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied for support ticket: " + id})
-				return
-			}
-		}
-		// Delete tickets that belong to the user.
-		if err := utils.DB.Where("id IN ? AND user_id = ?", input.IDs, userClaims.UserID).Delete(&models.SupportTicket{}).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete support tickets"})
-			return
-		}
-	} else {
-		// Admin can delete any support tickets.
-		if err := utils.DB.Where("id IN ?", input.IDs).Delete(&models.SupportTicket{}).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete support tickets"})
-			return
-		}
+	var deleted []models.SupportTicket
+	missing, err := deleteVisible(supportTicketPolicy(userClaims), &deleted, input.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete support tickets"})
+		return
+	}
+	if missing != "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Support ticket not found: " + missing})
+		return
+	}
+	for _, ticket := range deleted {
+		recordAuditChange(c, "support_ticket", ticket.ID.String(), ticket, nil)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Support tickets deleted successfully"})
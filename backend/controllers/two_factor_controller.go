@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// loginChallengeTTL is how long a user has to enter their TOTP code after
+// the password step of a login.
+const loginChallengeTTL = 5 * time.Minute
+
+var errInvalidSecondFactor = errors.New("invalid two-factor code")
+
+// recoveryCodeHashes splits the stored recovery code hashes.
+func recoveryCodeHashes(user models.User) []string {
+	if user.TOTPRecoveryCodes == "" {
+		return nil
+	}
+	return strings.Split(user.TOTPRecoveryCodes, ",")
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery
+// code. Both are consumed with a conditional update, so a code that has been
+// used once (or raced by a concurrent request) is refused. It reports whether
+// a recovery code was used.
+func verifySecondFactor(tx *gorm.DB, user models.User, code string) (bool, error) {
+	if counter, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND totp_last_counter < ?", user.ID, counter).
+			Update("totp_last_counter", counter)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return false, errInvalidSecondFactor
+		}
+		return false, nil
+	}
+
+	hashes := recoveryCodeHashes(user)
+	hash := utils.HashRecoveryCode(code)
+	for i, stored := range hashes {
+		if stored != hash {
+			continue
+		}
+		remaining := append(append([]string{}, hashes[:i]...), hashes[i+1:]...)
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND totp_recovery_codes = ?", user.ID, user.TOTPRecoveryCodes).
+			Update("totp_recovery_codes", strings.Join(remaining, ","))
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return false, errInvalidSecondFactor
+		}
+		return true, nil
+	}
+	return false, errInvalidSecondFactor
+}
+
+// loadCurrentUser fetches the authenticated user, responding on failure.
+func loadCurrentUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return user, false
+	}
+	if err := utils.DB.Where("id = ?", userClaims.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	return user, true
+}
+
+// EnrollTwoFactor starts TOTP enrolment by generating a secret. Two-factor
+// authentication is not enforced until the user confirms a code.
+func EnrollTwoFactor(c *gin.Context) {
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	if err := utils.DB.Model(&user).Update("totp_secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start enrolment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Add this account to your authenticator app, then confirm with a code",
+		"secret":      secret,
+		"otpauth_uri": utils.TOTPURI(secret, user.Email),
+	})
+}
+
+// ConfirmTwoFactor finishes enrolment with a code from the authenticator app
+// and returns the recovery codes, which are shown only once. Other sessions
+// are signed out so they have to log in with the second factor.
+func ConfirmTwoFactor(c *gin.Context) {
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start enrolment before confirming"})
+		return
+	}
+	counter, valid := utils.ValidateTOTP(user.TOTPSecret, input.Code, time.Now())
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	codes, hashes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":        true,
+			"totp_enabled_at":     time.Now(),
+			"totp_last_counter":   counter,
+			"totp_recovery_codes": strings.Join(hashes, ","),
+			"token_version":       gorm.Expr("token_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return revokeUserRefreshTokens(tx, user.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	if err := utils.DB.Where("id = ?", user.ID).First(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+	session, err := issueSession(user, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	response := session.response()
+	response["message"] = "Two-factor authentication enabled"
+	response["recovery_codes"] = codes
+	c.JSON(http.StatusOK, response)
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking a TOTP
+// or recovery code, and signs out every other session.
+func DisableTwoFactor(c *gin.Context) {
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := verifySecondFactor(tx, user, input.Code); err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":        false,
+			"totp_enabled_at":     nil,
+			"totp_secret":         "",
+			"totp_last_counter":   0,
+			"totp_recovery_codes": "",
+			"token_version":       gorm.Expr("token_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return revokeUserRefreshTokens(tx, user.ID)
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidSecondFactor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor code"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		}
+		return
+	}
+
+	if err := utils.DB.Where("id = ?", user.ID).First(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+	session, err := issueSession(user, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	response := session.response()
+	response["message"] = "Two-factor authentication disabled"
+	c.JSON(http.StatusOK, response)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after checking a TOTP code.
+func RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	codes, hashes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := verifySecondFactor(tx, user, input.Code); err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("totp_recovery_codes", strings.Join(hashes, ",")).Error
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidSecondFactor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor code"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Recovery codes regenerated",
+		"recovery_codes": codes,
+	})
+}
+
+// loginChallengeResponse is returned by LoginUser instead of a session when
+// the account has two-factor authentication enabled.
+func loginChallengeResponse(c *gin.Context, user models.User) {
+	challenge, err := utils.GenerateActionToken(user.ID.String(), user.Email, utils.PurposeLoginChallenge, loginChallengeTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"two_factor_required": true,
+		"challenge_token":     challenge,
+		"expires_in":          int(loginChallengeTTL.Seconds()),
+	})
+}
+
+// VerifyLoginChallenge completes a two-step login with the challenge token
+// from LoginUser and a TOTP or recovery code.
+func VerifyLoginChallenge(c *gin.Context) {
+	var input struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actionClaims, err := utils.ParseActionToken(input.ChallengeToken, utils.PurposeLoginChallenge)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", actionClaims.UserID).First(&user).Error; err != nil || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+	if !activeAccount(c, user) {
+		return
+	}
+
+	// Wrong codes count as failed logins, so the code cannot be brute forced
+	if !checkLoginThrottle(c, user.Email) {
+		return
+	}
+
+	var usedRecoveryCode bool
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		usedRecoveryCode, err = verifySecondFactor(tx, user, input.Code)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidSecondFactor) {
+			recordFailedLogin(c, user.Email, &user)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify two-factor code"})
+		}
+		return
+	}
+
+	session, err := issueSession(user, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	forgetLoginFailures(user)
+
+	response := session.response()
+	if usedRecoveryCode {
+		response["recovery_codes_remaining"] = len(recoveryCodeHashes(user)) - 1
+	}
+	c.JSON(http.StatusOK, response)
+}
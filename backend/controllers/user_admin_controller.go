@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errStatusUnchanged = errors.New("user already has this status")
+
+// statusVerbs describe each status change in responses.
+var statusVerbs = map[string]string{
+	models.UserStatusActive:    "reactivated",
+	models.UserStatusSuspended: "suspended",
+	models.UserStatusBanned:    "banned",
+}
+
+// activeAccount responds with 403 and returns false when the user's account
+// has been suspended or banned.
+func activeAccount(c *gin.Context, user models.User) bool {
+	if user.Status != models.UserStatusActive {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is " + user.Status})
+		return false
+	}
+	return true
+}
+
+// SuspendUser blocks a user from logging in until an admin reactivates them.
+func SuspendUser(c *gin.Context) {
+	changeUserStatus(c, models.UserStatusSuspended)
+}
+
+// BanUser permanently blocks a user. With "pause_campaigns" their pending and
+// active campaigns stop taking donations.
+func BanUser(c *gin.Context) {
+	changeUserStatus(c, models.UserStatusBanned)
+}
+
+// ReactivateUser lifts a suspension or ban.
+func ReactivateUser(c *gin.Context) {
+	changeUserStatus(c, models.UserStatusActive)
+}
+
+// changeUserStatus records a status change with its reason. Suspending or
+// banning a user signs them out everywhere.
+func changeUserStatus(c *gin.Context, status string) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason         string `json:"reason"`
+		PauseCampaigns bool   `json:"pause_campaigns"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if status != models.UserStatusActive && input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+
+	var user models.User
+	if err := utils.DB.Where("id = ?", c.Param("id")).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+
+	if user.ID.String() == userClaims.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change the status of your own account"})
+		return
+	}
+	// Only admins can act on other admins
+	if user.Role == utils.RoleAdmin && userClaims.Role != utils.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+	if user.Status == models.UserStatusErased {
+		c.JSON(http.StatusConflict, gin.H{"error": "Erased accounts cannot be changed"})
+		return
+	}
+
+	adminID, _ := uuid.Parse(userClaims.UserID)
+	now := time.Now()
+	previousStatus := user.Status
+	var pausedCampaigns int64
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if user.Status == status {
+			return errStatusUnchanged
+		}
+		if user.Role == utils.RoleAdmin && status != models.UserStatusActive {
+			var admins int64
+			if err := tx.Model(&models.User{}).
+				Where("role = ? AND status = ? AND id <> ?", utils.RoleAdmin, models.UserStatusActive, user.ID).
+				Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins == 0 {
+				return errLastAdmin
+			}
+		}
+
+		updates := map[string]interface{}{
+			"status":            status,
+			"status_reason":     input.Reason,
+			"status_changed_at": now,
+			"status_changed_by": adminID,
+		}
+		if status != models.UserStatusActive {
+			updates["token_version"] = gorm.Expr("token_version + 1")
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		if status == models.UserStatusActive {
+			return nil
+		}
+		if err := revokeUserRefreshTokens(tx, user.ID); err != nil {
+			return err
+		}
+
+		if status == models.UserStatusBanned && input.PauseCampaigns {
+			// Campaigns pending review are left alone: resuming a paused
+			// campaign makes it active, which would skip the review
+			result := tx.Model(&models.Campaign{}).
+				Where("creator_id = ? AND status = ?", user.ID, models.CampaignStatusActive).
+				Update("status", models.CampaignStatusPaused)
+			if result.Error != nil {
+				return result.Error
+			}
+			pausedCampaigns = result.RowsAffected
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errStatusUnchanged):
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already " + status})
+		return
+	case errors.Is(err, errLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last admin"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user status"})
+		return
+	}
+	recordAuditChange(c, "user", user.ID.String(),
+		gin.H{"status": previousStatus},
+		gin.H{"status": status, "reason": input.Reason, "paused_campaigns": pausedCampaigns})
+
+	response := gin.H{
+		"message": "User " + statusVerbs[status] + " successfully",
+		"user": gin.H{
+			"id":                user.ID,
+			"email":             user.Email,
+			"role":              user.Role,
+			"status":            status,
+			"status_reason":     input.Reason,
+			"status_changed_at": now,
+		},
+	}
+	if status == models.UserStatusBanned {
+		response["paused_campaigns"] = pausedCampaigns
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -110,17 +110,20 @@ func RegisterUser(c *gin.Context) {
 	var existingUser models.User
 	err := utils.DB.Where("email = ?", input.Email).First(&existingUser).Error
 	if err == nil {
-		// User already exists. Return a token for this user.
-		token, tokenErr := utils.GenerateToken(existingUser.ID.String(), existingUser.Email, existingUser.Role)
-		if tokenErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		// User already exists. Never hand out a token without a password check;
+		// guest donors are pointed at the claim flow instead.
+		if existingUser.PasswordHash == "" {
+			if err := sendAccountClaimEmail(existingUser); err != nil {
+				log.Printf("error sending account claim email to %s: %v", existingUser.Email, err)
+			}
+			c.JSON(http.StatusConflict, gin.H{
+				"error":          "An account with this email already exists. Check your email for a link to claim it.",
+				"claim_required": true,
+			})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"message": "User already registered. Returning existing token.",
-			"token":   token,
-		})
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
 		return
 	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		// Some other database error
@@ -160,8 +163,8 @@ func RegisterUser(c *gin.Context) {
 		return
 	}
 
-	// Generate a token for the newly registered user
-	token, err := utils.GenerateToken(user.ID.String(), user.Email, user.Role)
+	// Generate a token pair for the newly registered user
+	session, err := issueSession(user, false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
@@ -177,26 +180,28 @@ func RegisterUser(c *gin.Context) {
 		}
 	}(user.Email, user.FullName)
 
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("error sending verification email to %s: %v", user.Email, err)
+	}
+
 	// Respond with success
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully",
-		"token":   token,
-		"user": gin.H{
-			"id":         user.ID,
-			"email":      user.Email,
-			"full_name":  user.FullName,
-			"role":       user.Role,
-			"status":     user.Status,
-			"created_at": user.CreatedAt,
-			"updated_at": user.UpdatedAt,
-		},
-	})
+	response := session.response()
+	response["message"] = "User registered successfully"
+	response["user"] = gin.H{
+		"id":             user.ID,
+		"email":          user.Email,
+		"full_name":      user.FullName,
+		"role":           user.Role,
+		"status":         user.Status,
+		"email_verified": user.EmailVerified,
+		"created_at":     user.CreatedAt,
+		"updated_at":     user.UpdatedAt,
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 // Login a user and return a JWT token
 func LoginUser(c *gin.Context) {
-	log.Println("LoginUser handler called") // Debug log
-
 	var loginDetails struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
@@ -206,34 +211,45 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
-	log.Println("Login payload:", loginDetails) // Debug log
+	// Refuse attempts while the account or the client IP is backing off
+	if !checkLoginThrottle(c, loginDetails.Email) {
+		return
+	}
 
 	var user models.User
 	if err := utils.DB.Where("email = ?", loginDetails.Email).First(&user).Error; err != nil {
-		log.Println("User not found for email:", loginDetails.Email) // Debug log
+		recordFailedLogin(c, loginDetails.Email, nil)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	log.Println("Stored hashed password:", user.PasswordHash)           // Debug log
-	log.Println("Plaintext password from user:", loginDetails.Password) // Debug log
-
 	// Compare the password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginDetails.Password)); err != nil {
-		log.Println("Password comparison failed:", err) // Debug log
+		recordFailedLogin(c, loginDetails.Email, &user)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID.String(), user.Email, user.Role)
+	if !activeAccount(c, user) {
+		return
+	}
+
+	// With two-factor authentication on, the password only earns a challenge
+	// token that is exchanged for a session at /login/2fa
+	if user.TOTPEnabled {
+		loginChallengeResponse(c, user)
+		return
+	}
+
+	// Generate JWT access and refresh tokens
+	session, err := issueSession(user, false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
+	forgetLoginFailures(user)
 
-	log.Println("Token generated for user:", token) // Debug log
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, session.response())
 }
 
 // Get user details (protected route)
@@ -284,13 +300,15 @@ func GetUser(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "User registered successfully",
 		"user": gin.H{
-			"id":         user.ID,
-			"email":      user.Email,
-			"full_name":  user.FullName,
-			"role":       user.Role,
-			"status":     user.Status,
-			"created_at": user.CreatedAt,
-			"updated_at": user.UpdatedAt,
+			"id":                 user.ID,
+			"email":              user.Email,
+			"full_name":          user.FullName,
+			"role":               user.Role,
+			"status":             user.Status,
+			"email_verified":     user.EmailVerified,
+			"two_factor_enabled": user.TOTPEnabled,
+			"created_at":         user.CreatedAt,
+			"updated_at":         user.UpdatedAt,
 		},
 	})
 }
@@ -311,21 +329,15 @@ func UpdateUser(c *gin.Context) {
 
 	// Bind the input fields
 	var input struct {
-		FullName string `json:"full_name,omitempty"`
-		Role     string `json:"role,omitempty"`
+		FullName      string `json:"full_name,omitempty"`
+		Role          string `json:"role,omitempty"`
+		UpdatesOptOut *bool  `json:"updates_opt_out,omitempty"` // Stop campaign update notifications and emails
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Validate role
-	allowedRoles := map[string]bool{"donor": true, "campaign_creator": true}
-	if input.Role != "" && !allowedRoles[input.Role] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
-		return
-	}
-
 	// Fetch the user using the ID from the claims
 	var user models.User
 	if err := utils.DB.Where("id = ?", userClaims.UserID).First(&user).Error; err != nil {
@@ -333,12 +345,19 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	// Roles are assigned by admins; sending the current role is accepted so
+	// clients can post back the whole profile
+	if input.Role != "" && input.Role != user.Role {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Role changes must be made by an administrator"})
+		return
+	}
+
 	// Update the user fields
 	if input.FullName != "" {
 		user.FullName = input.FullName
 	}
-	if input.Role != "" {
-		user.Role = input.Role
+	if input.UpdatesOptOut != nil {
+		user.UpdatesOptOut = *input.UpdatesOptOut
 	}
 
 	// Save the updated user to the database
@@ -350,13 +369,16 @@ func UpdateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User updated successfully",
 		"user": gin.H{
-			"id":         user.ID,
-			"email":      user.Email,
-			"full_name":  user.FullName,
-			"role":       user.Role,
-			"status":     user.Status,
-			"created_at": user.CreatedAt,
-			"updated_at": user.UpdatedAt,
+			"id":                 user.ID,
+			"email":              user.Email,
+			"full_name":          user.FullName,
+			"role":               user.Role,
+			"status":             user.Status,
+			"email_verified":     user.EmailVerified,
+			"two_factor_enabled": user.TOTPEnabled,
+			"updates_opt_out":    user.UpdatesOptOut,
+			"created_at":         user.CreatedAt,
+			"updated_at":         user.UpdatedAt,
 		},
 	})
 }
@@ -382,38 +404,12 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
-	// Delete the user record from the database
-	if err := utils.DB.Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "User deleted successfully",
-	})
+	// Donations and campaigns must outlive the account for accounting, so the
+	// user's personal data is erased instead of deleting the row
+	eraseUserResponse(c, user, nil)
 }
 
 func GetAllUsers(c *gin.Context) {
-	// Retrieve claims from the context
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	// Type assertion to get the Claims struct
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-		return
-	}
-	log.Println("User Role:", userClaims.Role)
-	// Check if the user is an admin
-	if userClaims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
-		return
-	}
-
 	// Fetch all users from the database
 	var users []models.User
 	if err := utils.DB.Select("id, email, full_name, role, status, created_at, updated_at").Find(&users).Error; err != nil {
@@ -424,34 +420,3 @@ func GetAllUsers(c *gin.Context) {
 	// Return the list of users
 	c.JSON(http.StatusOK, gin.H{"users": users})
 }
-
-func RefreshToken(c *gin.Context) {
-	// Retrieve claims from the context
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-		return
-	}
-
-	// Fetch the latest user information from the database
-	var user models.User
-	if err := utils.DB.Where("id = ?", userClaims.UserID).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-		return
-	}
-
-	// Generate a new token with the updated role
-	token, err := utils.GenerateToken(user.ID.String(), user.Email, user.Role)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"token": token})
-}
@@ -12,7 +12,9 @@ import (
 	"gorm.io/gorm"
 )
 
-// CreateWithdrawal creates a new withdrawal record.
+// CreateWithdrawal creates a new withdrawal record. Only the campaign's
+// owners and finance team members, or anyone with withdrawal:approve, can
+// request one.
 func CreateWithdrawal(c *gin.Context) {
 	// Retrieve JWT claims (assuming a protected route)
 	claims, exists := c.Get("claims")
@@ -20,7 +22,7 @@ func CreateWithdrawal(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
-	_, ok := claims.(*utils.Claims)
+	userClaims, ok := claims.(*utils.Claims)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 		return
@@ -29,8 +31,7 @@ func CreateWithdrawal(c *gin.Context) {
 	// Bind input JSON
 	var input struct {
 		CampaignID string  `json:"campaign_id" binding:"required"`
-		Amount     float64 `json:"amount" binding:"required"`
-		Status     string  `json:"status"` // optional; default to "pending"
+		Amount     float64 `json:"amount" binding:"required,gt=0"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -43,16 +44,22 @@ func CreateWithdrawal(c *gin.Context) {
 		return
 	}
 
-	status := input.Status
-	if status == "" {
-		status = "pending"
+	var campaign models.Campaign
+	if err := utils.DB.Where("id = ?", campaignID).First(&campaign).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+	if !utils.HasPermission(userClaims.Role, utils.PermWithdrawalApprove) &&
+		!models.CampaignRoleCan(campaignRole(campaign, userClaims.UserID), models.CampaignCanFinance) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
 	}
 
 	withdrawal := models.Withdrawal{
 		ID:         uuid.New(),
 		CampaignID: campaignID,
 		Amount:     input.Amount,
-		Status:     status,
+		Status:     "pending", // Only withdrawal:approve moves it on
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
@@ -61,6 +68,7 @@ func CreateWithdrawal(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create withdrawal"})
 		return
 	}
+	recordAuditChange(c, "withdrawal", withdrawal.ID.String(), nil, withdrawal)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":    "Withdrawal created successfully",
@@ -68,11 +76,17 @@ func CreateWithdrawal(c *gin.Context) {
 	})
 }
 
-// GetWithdrawalByID retrieves a withdrawal by its ID.
+// GetWithdrawalByID retrieves a withdrawal by its ID. Only the campaign's
+// owners and finance team members and users with withdrawal:read can see it;
+// others get a 404.
 func GetWithdrawalByID(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
 	id := c.Param("id")
 	var withdrawal models.Withdrawal
-	if err := utils.DB.Where("id = ?", id).First(&withdrawal).Error; err != nil {
+	if err := utils.DB.Scopes(withdrawalPolicy(userClaims)).Where("id = ?", id).First(&withdrawal).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Withdrawal not found"})
 		} else {
@@ -83,40 +97,42 @@ func GetWithdrawalByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"withdrawal": withdrawal})
 }
 
-// ListWithdrawals lists all withdrawals, optionally filtered by campaign_id.
+// ListWithdrawals lists the withdrawals visible to the caller, optionally filtered by campaign_id.
 func ListWithdrawals(c *gin.Context) {
+	userClaims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
 	campaignID := c.Query("campaign_id")
 
-	query := utils.DB.Model(&models.Withdrawal{})
+	page, ok := newPage(c, "withdrawals", newestFirst)
+	if !ok {
+		return
+	}
+
+	query := utils.DB.Model(&models.Withdrawal{}).Scopes(withdrawalPolicy(userClaims))
 	if campaignID != "" {
 		query = query.Where("campaign_id = ?", campaignID)
 	}
 
 	var withdrawals []models.Withdrawal
-	if err := query.Find(&withdrawals).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch withdrawals"})
+	result, ok := findPage(c, page, query, &withdrawals, "Failed to fetch withdrawals")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"withdrawals": withdrawals})
+	c.JSON(http.StatusOK, result.Apply(gin.H{"withdrawals": withdrawals}))
 }
 
-// UpdateWithdrawal updates a withdrawal record by its ID. (Admin-only)
+// UpdateWithdrawal updates a withdrawal record by its ID. (Requires withdrawal:approve)
 func UpdateWithdrawal(c *gin.Context) {
-	id := c.Param("id")
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok || userClaims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	userClaims, ok := currentClaims(c)
+	if !ok {
 		return
 	}
-
+	id := c.Param("id")
 	var withdrawal models.Withdrawal
-	if err := utils.DB.Where("id = ?", id).First(&withdrawal).Error; err != nil {
+	if err := utils.DB.Scopes(withdrawalPolicy(userClaims)).Where("id = ?", id).First(&withdrawal).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Withdrawal not found"})
 		} else {
@@ -135,6 +151,7 @@ func UpdateWithdrawal(c *gin.Context) {
 		return
 	}
 
+	before := withdrawal
 	if input.Amount != 0 {
 		withdrawal.Amount = input.Amount
 	}
@@ -150,6 +167,7 @@ func UpdateWithdrawal(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update withdrawal"})
 		return
 	}
+	recordAuditChange(c, "withdrawal", withdrawal.ID.String(), before, withdrawal)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":    "Withdrawal updated successfully",
@@ -157,16 +175,10 @@ func UpdateWithdrawal(c *gin.Context) {
 	})
 }
 
-// BulkDeleteWithdrawals deletes multiple withdrawal records by their IDs. (Admin-only)
+// BulkDeleteWithdrawals deletes multiple withdrawal records by their IDs. (Requires withdrawal:delete)
 func BulkDeleteWithdrawals(c *gin.Context) {
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims, ok := claims.(*utils.Claims)
-	if !ok || userClaims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	userClaims, ok := currentClaims(c)
+	if !ok {
 		return
 	}
 
@@ -178,10 +190,19 @@ func BulkDeleteWithdrawals(c *gin.Context) {
 		return
 	}
 
-	if err := utils.DB.Where("id IN ?", input.IDs).Delete(&models.Withdrawal{}).Error; err != nil {
+	var deleted []models.Withdrawal
+	missing, err := deleteVisible(withdrawalPolicy(userClaims), &deleted, input.IDs)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete withdrawals"})
 		return
 	}
+	if missing != "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Withdrawal not found: " + missing})
+		return
+	}
+	for _, withdrawal := range deleted {
+		recordAuditChange(c, "withdrawal", withdrawal.ID.String(), withdrawal, nil)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Withdrawals deleted successfully"})
 }
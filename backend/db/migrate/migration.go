@@ -3,6 +3,8 @@ package migrate
 import (
     "backend/models"
     "backend/utils"
+
+    "gorm.io/gorm/clause"
 )
 
 func RunMigrations() {
@@ -19,5 +21,46 @@ func RunMigrations() {
         &models.Notification{},
         &models.SupportTicket{},
         &models.Withdrawal{},
+        &models.PasswordResetToken{},
+        &models.RefreshToken{},
+        &models.Role{},
+        &models.RolePermission{},
+        &models.LoginThrottle{},
+        &models.ExternalIdentity{},
+        &models.OIDCLoginState{},
+        &models.APIKey{},
+        &models.AuditLog{},
+        &models.CampaignUpdate{},
+        &models.CampaignMilestone{},
+        &models.CampaignFollower{},
+        &models.RewardTier{},
+        &models.RewardClaim{},
+        &models.CampaignMember{},
+        &models.CampaignInvitation{},
+        &models.CampaignSlugRedirect{},
+        &models.CampaignRevision{},
     )
+
+    seedRoles()
+}
+
+// seedRoles creates the built-in roles and their default permissions without
+// touching grants an admin has already changed.
+func seedRoles() {
+    roles := []models.Role{
+        {Name: utils.RoleAdmin, Description: "Full access to every resource", IsSystem: true},
+        {Name: utils.RoleCampaignCreator, Description: "Runs fundraising campaigns", IsSystem: true},
+        {Name: utils.RoleDonor, Description: "Donates to campaigns", IsSystem: true},
+    }
+    for _, role := range roles {
+        var created int64
+        utils.DB.Model(&models.Role{}).Where("name = ?", role.Name).Count(&created)
+        if created > 0 {
+            continue
+        }
+        utils.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&role)
+        for _, perm := range utils.DefaultRolePermissions[role.Name] {
+            utils.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RolePermission{RoleName: role.Name, Permission: perm})
+        }
+    }
 }
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS PasswordResetTokens;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS token_version;
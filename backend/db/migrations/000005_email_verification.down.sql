@@ -0,0 +1,5 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS email_verified_at;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS email_verified;
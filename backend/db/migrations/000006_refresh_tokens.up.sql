@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS RefreshTokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES Users(id),
+    family_id UUID NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by_id UUID REFERENCES RefreshTokens(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refreshtokens_user_id ON RefreshTokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refreshtokens_family_id ON RefreshTokens(family_id);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS Roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS RolePermissions (
+    role_name VARCHAR(50) NOT NULL REFERENCES Roles(name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL,
+    PRIMARY KEY (role_name, permission)
+);
+
+INSERT INTO Roles (name, description, is_system) VALUES
+    ('admin', 'Full access to every resource', TRUE),
+    ('campaign_creator', 'Runs fundraising campaigns', TRUE),
+    ('donor', 'Donates to campaigns', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO RolePermissions (role_name, permission) VALUES
+    ('campaign_creator', 'campaign:create'),
+    ('campaign_creator', 'media:create'),
+    ('campaign_creator', 'withdrawal:create')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+ALTER TABLE refreshtokens
+DROP COLUMN IF EXISTS two_factor;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS totp_recovery_codes,
+DROP COLUMN IF EXISTS totp_last_counter,
+DROP COLUMN IF EXISTS totp_enabled_at,
+DROP COLUMN IF EXISTS totp_enabled,
+DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,9 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64),
+ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS totp_last_counter BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS totp_recovery_codes TEXT;
+
+ALTER TABLE refreshtokens
+ADD COLUMN IF NOT EXISTS two_factor BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS status_changed_by,
+DROP COLUMN IF EXISTS status_changed_at,
+DROP COLUMN IF EXISTS status_reason;
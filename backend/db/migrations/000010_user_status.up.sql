@@ -0,0 +1,4 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS status_reason TEXT,
+ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS status_changed_by UUID REFERENCES Users(id);
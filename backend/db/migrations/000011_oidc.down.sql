@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS OIDCLoginStates;
+DROP TABLE IF EXISTS ExternalIdentities;
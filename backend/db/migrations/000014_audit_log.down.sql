@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS AuditLogs;
+DROP FUNCTION IF EXISTS auditlogs_append_only();
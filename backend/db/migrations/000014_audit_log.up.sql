@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS AuditLogs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id UUID,
+    actor_role VARCHAR(50),
+    action VARCHAR(255) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(255),
+    before TEXT,
+    after TEXT,
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    created_at TIMESTAMP NOT NULL,
+    prev_hash VARCHAR(64),
+    hash VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_auditlogs_actor_id ON AuditLogs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_auditlogs_action ON AuditLogs(action);
+CREATE INDEX IF NOT EXISTS idx_auditlogs_resource ON AuditLogs(resource_type, resource_id);
+
+-- The audit log is append-only
+CREATE OR REPLACE FUNCTION auditlogs_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'auditlogs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS auditlogs_no_update ON AuditLogs;
+CREATE TRIGGER auditlogs_no_update
+BEFORE UPDATE OR DELETE ON AuditLogs
+FOR EACH ROW EXECUTE FUNCTION auditlogs_append_only();
+
+DROP TRIGGER IF EXISTS auditlogs_no_truncate ON AuditLogs;
+CREATE TRIGGER auditlogs_no_truncate
+BEFORE TRUNCATE ON AuditLogs
+FOR EACH STATEMENT EXECUTE FUNCTION auditlogs_append_only();
@@ -0,0 +1,9 @@
+UPDATE campaigns SET status = 'pending' WHERE status IN ('draft', 'pending_review', 'rejected');
+
+ALTER TABLE campaigns
+ALTER COLUMN status SET DEFAULT 'pending';
+
+ALTER TABLE campaigns
+DROP COLUMN IF EXISTS rejection_reason,
+DROP COLUMN IF EXISTS rejected_at,
+DROP COLUMN IF EXISTS rejected_by;
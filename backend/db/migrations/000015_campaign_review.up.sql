@@ -0,0 +1,10 @@
+ALTER TABLE campaigns
+ADD COLUMN IF NOT EXISTS rejected_by UUID REFERENCES Users(id),
+ADD COLUMN IF NOT EXISTS rejected_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
+
+ALTER TABLE campaigns
+ALTER COLUMN status SET DEFAULT 'draft';
+
+-- Campaigns created before the review workflow were waiting for approval
+UPDATE campaigns SET status = 'pending_review' WHERE status = 'pending';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_campaigns_search;
+
+ALTER TABLE campaigns
+DROP COLUMN IF EXISTS search_vector;
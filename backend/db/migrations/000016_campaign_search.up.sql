@@ -0,0 +1,10 @@
+-- Title matches weigh more than category matches, which weigh more than
+-- description matches
+ALTER TABLE campaigns
+ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_search ON campaigns USING GIN (search_vector);
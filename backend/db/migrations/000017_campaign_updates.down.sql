@@ -0,0 +1,5 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS updates_opt_out;
+
+DROP TABLE IF EXISTS CampaignUpdateMedia;
+DROP TABLE IF EXISTS CampaignUpdates;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS CampaignUpdates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES Users(id),
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaignupdates_campaign_id ON CampaignUpdates(campaign_id);
+
+CREATE TABLE IF NOT EXISTS CampaignUpdateMedia (
+    campaign_update_id UUID NOT NULL REFERENCES CampaignUpdates(id) ON DELETE CASCADE,
+    media_file_id UUID NOT NULL REFERENCES MediaFiles(id) ON DELETE CASCADE,
+    PRIMARY KEY (campaign_update_id, media_file_id)
+);
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS updates_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS CampaignFollowers;
+DROP TABLE IF EXISTS CampaignMilestones;
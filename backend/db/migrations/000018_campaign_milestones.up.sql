@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS CampaignMilestones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    amount NUMERIC(12,2) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    reached_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaignmilestones_amount ON CampaignMilestones(campaign_id, amount);
+
+CREATE TABLE IF NOT EXISTS CampaignFollowers (
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES Users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (campaign_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaignfollowers_user_id ON CampaignFollowers(user_id);
+
+-- Donors follow the campaigns they donated to, except anonymously
+INSERT INTO CampaignFollowers (campaign_id, user_id)
+SELECT DISTINCT campaign_id, donor_id FROM donations
+WHERE donor_id IS NOT NULL AND is_anonymous IS NOT TRUE
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS RewardClaims;
+DROP TABLE IF EXISTS RewardTiers;
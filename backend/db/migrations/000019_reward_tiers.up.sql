@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS RewardTiers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    minimum_amount NUMERIC(12,2) NOT NULL,
+    quantity_limit INTEGER,
+    quantity_claimed INTEGER NOT NULL DEFAULT 0,
+    estimated_delivery TIMESTAMP,
+    requires_shipping BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (quantity_limit IS NULL OR quantity_claimed <= quantity_limit)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rewardtiers_campaign_id ON RewardTiers(campaign_id);
+
+CREATE TABLE IF NOT EXISTS RewardClaims (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    donation_id UUID NOT NULL UNIQUE REFERENCES Donations(id) ON DELETE CASCADE,
+    reward_tier_id UUID NOT NULL REFERENCES RewardTiers(id),
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    donor_id UUID NOT NULL REFERENCES Users(id) ON DELETE CASCADE,
+    contact_email VARCHAR(255) NOT NULL,
+    contact_phone VARCHAR(50),
+    shipping_name VARCHAR(255),
+    address_line1 VARCHAR(255),
+    address_line2 VARCHAR(255),
+    city VARCHAR(100),
+    region VARCHAR(100),
+    postal_code VARCHAR(20),
+    country VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    tracking_number VARCHAR(255),
+    shipped_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rewardclaims_reward_tier_id ON RewardClaims(reward_tier_id);
+CREATE INDEX IF NOT EXISTS idx_rewardclaims_campaign_id ON RewardClaims(campaign_id);
+CREATE INDEX IF NOT EXISTS idx_rewardclaims_donor_id ON RewardClaims(donor_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS CampaignInvitations;
+DROP TABLE IF EXISTS CampaignMembers;
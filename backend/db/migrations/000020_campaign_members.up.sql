@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS CampaignMembers (
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES Users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    invited_by UUID REFERENCES Users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (campaign_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaignmembers_user_id ON CampaignMembers(user_id);
+
+CREATE TABLE IF NOT EXISTS CampaignInvitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by UUID NOT NULL REFERENCES Users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigninvitations_campaign_id ON CampaignInvitations(campaign_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS CampaignSlugRedirects;
+
+DROP INDEX IF EXISTS idx_campaigns_slug;
+
+ALTER TABLE campaigns
+DROP COLUMN IF EXISTS slug;
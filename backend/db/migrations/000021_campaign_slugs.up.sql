@@ -0,0 +1,34 @@
+ALTER TABLE campaigns
+ADD COLUMN IF NOT EXISTS slug VARCHAR(80);
+
+-- Existing campaigns get a slug made from their title. Letters without a
+-- plain ASCII form are dropped, and later campaigns sharing a slug get the
+-- start of their ID appended.
+WITH made AS (
+    SELECT id, created_at, trim(BOTH '-' FROM left(trim(BOTH '-' FROM regexp_replace(
+        translate(lower(title), 'àáâãäåçèéêëìíîïñòóôõöùúûüýÿ', 'aaaaaaceeeeiiiinooooouuuuyy'),
+        '[^a-z0-9]+', '-', 'g')), 70)) AS base
+    FROM campaigns
+    WHERE slug IS NULL
+), numbered AS (
+    SELECT id, CASE WHEN length(base) < 3 THEN 'campaign' ELSE base END AS base,
+        row_number() OVER (
+            PARTITION BY CASE WHEN length(base) < 3 THEN 'campaign' ELSE base END
+            ORDER BY created_at, id
+        ) AS n
+    FROM made
+)
+UPDATE campaigns
+SET slug = CASE WHEN numbered.n = 1 THEN numbered.base ELSE numbered.base || '-' || left(campaigns.id::text, 8) END
+FROM numbered
+WHERE numbered.id = campaigns.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaigns_slug ON campaigns(slug);
+
+CREATE TABLE IF NOT EXISTS CampaignSlugRedirects (
+    slug VARCHAR(80) PRIMARY KEY,
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaignslugredirects_campaign_id ON CampaignSlugRedirects(campaign_id);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS CampaignRevisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES Campaigns(id) ON DELETE CASCADE,
+    editor_id UUID NOT NULL REFERENCES Users(id),
+    changes TEXT NOT NULL,
+    reverted_to UUID REFERENCES CampaignRevisions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaignrevisions_campaign_id ON CampaignRevisions(campaign_id);
+
+-- Earlier edits were not kept, so existing campaigns start their history with
+-- the content they have now
+INSERT INTO CampaignRevisions (campaign_id, editor_id, changes, created_at)
+SELECT id, creator_id, json_build_object(
+    'title', json_build_object('from', NULL, 'to', title),
+    'description', json_build_object('from', NULL, 'to', description),
+    'target_amount', json_build_object('from', NULL, 'to', target_amount),
+    'deadline', json_build_object('from', NULL, 'to', to_char(deadline, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+    'category', json_build_object('from', NULL, 'to', category)
+)::text, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+FROM campaigns
+WHERE creator_id IS NOT NULL;
@@ -0,0 +1,47 @@
+ALTER TABLE comments
+DROP CONSTRAINT IF EXISTS comments_campaign_id_fkey,
+ADD CONSTRAINT comments_campaign_id_fkey FOREIGN KEY (campaign_id) REFERENCES campaigns(id);
+
+ALTER TABLE mediafiles
+DROP CONSTRAINT IF EXISTS mediafiles_campaign_id_fkey,
+ADD CONSTRAINT mediafiles_campaign_id_fkey FOREIGN KEY (campaign_id) REFERENCES campaigns(id);
+
+ALTER TABLE campaignanalytics
+DROP CONSTRAINT IF EXISTS campaignanalytics_campaign_id_fkey,
+ADD CONSTRAINT campaignanalytics_campaign_id_fkey FOREIGN KEY (campaign_id) REFERENCES campaigns(id);
+
+-- Deleted milestones would clash with the full unique index
+DELETE FROM campaignmilestones WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_campaignmilestones_amount;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaignmilestones_amount ON campaignmilestones(campaign_id, amount);
+
+-- Other rows that are still soft deleted come back
+DROP INDEX IF EXISTS idx_campaigns_deleted_at;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_campaignupdates_deleted_at;
+ALTER TABLE campaignupdates DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_campaignmilestones_deleted_at;
+ALTER TABLE campaignmilestones DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_rewardtiers_deleted_at;
+ALTER TABLE rewardtiers DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_comments_deleted_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_mediafiles_deleted_at;
+ALTER TABLE mediafiles DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_notifications_deleted_at;
+ALTER TABLE notifications DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_supporttickets_deleted_at;
+ALTER TABLE supporttickets DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_paymenttransactions_deleted_at;
+ALTER TABLE paymenttransactions DROP COLUMN IF EXISTS deleted_at;
+
+DROP INDEX IF EXISTS idx_withdrawals_deleted_at;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS deleted_at;
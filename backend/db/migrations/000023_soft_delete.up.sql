@@ -0,0 +1,66 @@
+ALTER TABLE campaigns
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_deleted_at ON campaigns(deleted_at);
+
+ALTER TABLE campaignupdates
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_campaignupdates_deleted_at ON campaignupdates(deleted_at);
+
+ALTER TABLE campaignmilestones
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_campaignmilestones_deleted_at ON campaignmilestones(deleted_at);
+
+ALTER TABLE rewardtiers
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_rewardtiers_deleted_at ON rewardtiers(deleted_at);
+
+ALTER TABLE comments
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(deleted_at);
+
+ALTER TABLE mediafiles
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_mediafiles_deleted_at ON mediafiles(deleted_at);
+
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted_at ON notifications(deleted_at);
+
+ALTER TABLE supporttickets
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_supporttickets_deleted_at ON supporttickets(deleted_at);
+
+ALTER TABLE paymenttransactions
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_paymenttransactions_deleted_at ON paymenttransactions(deleted_at);
+
+ALTER TABLE withdrawals
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_withdrawals_deleted_at ON withdrawals(deleted_at);
+
+-- The amount of a deleted milestone can be used again
+DROP INDEX IF EXISTS idx_campaignmilestones_amount;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaignmilestones_amount ON campaignmilestones(campaign_id, amount) WHERE deleted_at IS NULL;
+
+-- Purging a deleted campaign removes its comments, media files and analytics
+ALTER TABLE comments
+DROP CONSTRAINT IF EXISTS comments_campaign_id_fkey,
+ADD CONSTRAINT comments_campaign_id_fkey FOREIGN KEY (campaign_id) REFERENCES campaigns(id) ON DELETE CASCADE;
+
+ALTER TABLE mediafiles
+DROP CONSTRAINT IF EXISTS mediafiles_campaign_id_fkey,
+ADD CONSTRAINT mediafiles_campaign_id_fkey FOREIGN KEY (campaign_id) REFERENCES campaigns(id) ON DELETE CASCADE;
+
+ALTER TABLE campaignanalytics
+DROP CONSTRAINT IF EXISTS campaignanalytics_campaign_id_fkey,
+ADD CONSTRAINT campaignanalytics_campaign_id_fkey FOREIGN KEY (campaign_id) REFERENCES campaigns(id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+UPDATE users
+SET status = 'inactive'
+WHERE status = 'suspended' AND status_reason = 'Inactive before account statuses were introduced';
@@ -0,0 +1,7 @@
+-- "inactive" predates account statuses; such accounts could not log in, which
+-- is what suspended means now
+UPDATE users
+SET status = 'suspended',
+    status_reason = COALESCE(NULLIF(status_reason, ''), 'Inactive before account statuses were introduced'),
+    status_changed_at = COALESCE(status_changed_at, NOW())
+WHERE status = 'inactive';
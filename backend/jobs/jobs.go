@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"time"
+
+	"backend/controllers"
+	"backend/utils"
+)
+
+// Default returns the jobs the backend runs, with intervals taken from the
+// environment.
+func Default() []Job {
+	return []Job{
+		{
+			Name:     "campaign-lifecycle",
+			Interval: time.Duration(utils.GetEnvInt("CAMPAIGN_LIFECYCLE_INTERVAL_SECONDS", 300)) * time.Second,
+			Run:      controllers.RunCampaignLifecycle,
+		},
+		{
+			Name:     "purge-deleted",
+			Interval: time.Duration(utils.GetEnvInt("PURGE_DELETED_INTERVAL_SECONDS", 3600)) * time.Second,
+			Run:      controllers.PurgeDeletedRecords,
+		},
+	}
+}
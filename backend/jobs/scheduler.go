@@ -0,0 +1,86 @@
+// Package jobs runs the backend's periodic background work.
+package jobs
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"backend/utils"
+)
+
+// Job is a task the scheduler runs on a fixed interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start runs every job on its interval, in the background, until ctx is
+// cancelled. Each run holds a Postgres advisory lock named after the job, so
+// when several replicas are running only one of them runs a job at a time.
+// Jobs with no interval are disabled.
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		if job.Interval <= 0 {
+			log.Printf("job %s is disabled", job.Name)
+			continue
+		}
+		go schedule(ctx, job)
+	}
+}
+
+func schedule(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := RunLocked(ctx, job); err != nil {
+			log.Printf("job %s failed: %v", job.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunLocked runs the job once if no other replica is running it. It reports
+// whether the job ran.
+func RunLocked(ctx context.Context, job Job) (bool, error) {
+	sqlDB, err := utils.DB.DB()
+	if err != nil {
+		return false, err
+	}
+	// Advisory locks belong to a session, so take and release it on one
+	// connection and let the job use the pool as usual
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	key := LockKey(job.Name)
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Printf("job %s: failed to release lock: %v", job.Name, err)
+		}
+	}()
+
+	return true, job.Run(ctx)
+}
+
+// LockKey is the advisory lock key for the named job.
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("impacta:job:" + name))
+	return int64(h.Sum64())
+}
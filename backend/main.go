@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 
+	"backend/jobs"
+	"backend/pagination"
 	"backend/routes"
 	"backend/utils"
 
@@ -14,12 +17,23 @@ func main() {
 	// Connect to the database
 	utils.ConnectDB()
 
+	// Load the JWT signing keys (fails fast on bad configuration)
+	utils.InitKeyRing()
+
+	// Load the pagination cursor secret (fails fast when missing)
+	pagination.InitCursorSecret()
+
 	// Init Prometheus metrics
 	utils.InitMetrics()
 
 	// Uncomment if you want to run database migrations
 	// migrate.RunMigrations()
 
+	// Start the background jobs (safe to run on every replica)
+	if utils.GetEnv("JOBS_ENABLED", "true") == "true" {
+		jobs.Start(context.Background(), jobs.Default()...)
+	}
+
 	// Print a startup message
 	log.Println("Starting Impacta Backend...")
 
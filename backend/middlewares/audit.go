@@ -0,0 +1,176 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// auditChainLock is the advisory lock key that serialises writes to the
+// audit log, so that every entry chains onto the one before it.
+const auditChainLock = 0x617564697400 // "audit"
+
+// auditRetries is how many times entries that could not be written with the
+// request are tried again.
+const auditRetries = 5
+
+// AuditLog records every successful mutation of the route in the audit log.
+// Handlers that know what they changed leave utils.AuditChange values under
+// utils.AuditChangesKey; otherwise one entry is written for the record named
+// by the :id or :name route parameter, or else the last one. Reads are not
+// recorded.
+//
+// The entry is written once the handler has made its change, so a failure
+// cannot undo it. An entry that cannot be written is retried in the
+// background, and logged in full if it still fails, rather than reporting
+// the change as failed.
+func AuditLog(resourceType string) gin.HandlerFunc {
+	return auditLog(resourceType, "")
+}
+
+// AuditLogFor is AuditLog for routes that users and moderators share. Only
+// requests by a role holding permission are recorded.
+func AuditLogFor(resourceType, permission string) gin.HandlerFunc {
+	return auditLog(resourceType, permission)
+}
+
+func auditLog(resourceType, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if permission != "" && !callerHasPermission(c, permission) {
+			c.Next()
+			return
+		}
+
+		c.Next()
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		records, err := auditRecords(c, auditChanges(c, resourceType))
+		if err != nil {
+			log.Printf("Failed to build audit log for %s %s: %v", c.Request.Method, c.FullPath(), err)
+			return
+		}
+		if err := appendAuditRecords(records); err != nil {
+			log.Printf("Failed to write audit log for %s %s, retrying: %v", c.Request.Method, c.FullPath(), err)
+			go retryAuditRecords(records)
+		}
+	}
+}
+
+func callerHasPermission(c *gin.Context, permission string) bool {
+	claims, ok := c.Get("claims")
+	if !ok {
+		return false
+	}
+	userClaims, ok := claims.(*utils.Claims)
+	return ok && utils.HasPermission(userClaims.Role, permission)
+}
+
+// auditChanges returns what the handler reported changing, or else one
+// change for the record named in the route.
+func auditChanges(c *gin.Context, resourceType string) []utils.AuditChange {
+	if value, ok := c.Get(utils.AuditChangesKey); ok {
+		if changes, _ := value.([]utils.AuditChange); len(changes) > 0 {
+			return changes
+		}
+	}
+	resourceID := c.Param("id")
+	if resourceID == "" {
+		resourceID = c.Param("name")
+	}
+	if resourceID == "" && len(c.Params) > 0 {
+		resourceID = c.Params[len(c.Params)-1].Value
+	}
+	return []utils.AuditChange{{ResourceType: resourceType, ResourceID: resourceID}}
+}
+
+// auditRecords builds the entries for the changes of the request. They are
+// chained onto the log when they are appended.
+func auditRecords(c *gin.Context, changes []utils.AuditChange) ([]models.AuditLog, error) {
+	entry := models.AuditLog{
+		Action:    c.Request.Method + " " + c.FullPath(),
+		IP:        c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond), // Postgres keeps microseconds
+	}
+	if claims, ok := c.Get("claims"); ok {
+		if userClaims, ok := claims.(*utils.Claims); ok {
+			if actorID, err := uuid.Parse(userClaims.UserID); err == nil {
+				entry.ActorID = &actorID
+			}
+			entry.ActorRole = userClaims.Role
+		}
+	}
+
+	records := make([]models.AuditLog, 0, len(changes))
+	for _, change := range changes {
+		before, after, err := utils.AuditDiff(change.Before, change.After)
+		if err != nil {
+			return nil, err
+		}
+		record := entry
+		record.ResourceType = change.ResourceType
+		record.ResourceID = change.ResourceID
+		record.Before = before
+		record.After = after
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// appendAuditRecords writes the records at the end of the hash chain.
+func appendAuditRecords(records []models.AuditLog) error {
+	return utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLock).Error; err != nil {
+			return err
+		}
+		var last models.AuditLog
+		if err := tx.Select("hash").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+
+		prevHash := last.Hash
+		for _, record := range records {
+			record.PrevHash = prevHash
+			record.Hash = record.ComputeHash()
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+			prevHash = record.Hash
+		}
+		return nil
+	})
+}
+
+// retryAuditRecords keeps trying to write records the request could not,
+// backing off between attempts. Records that never make it are logged so
+// they can be restored by hand.
+func retryAuditRecords(records []models.AuditLog) {
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		time.Sleep(delay)
+		err := appendAuditRecords(records)
+		if err == nil {
+			return
+		}
+		if attempt == auditRetries {
+			data, _ := json.Marshal(records)
+			log.Printf("Giving up on audit log entries after %d retries: %v: %s", attempt, err, data)
+			return
+		}
+		delay *= 2
+	}
+}
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"backend/models"
 	"backend/utils"
 	"net/http"
 	"strings"
@@ -18,15 +19,48 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := utils.ParseToken(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+		authenticateJWT(c, tokenString)
+	}
+}
+
+// OptionalJWTAuth lets anonymous requests through but authenticates a
+// request that carries a token, so public handlers can show the caller more.
+func OptionalJWTAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
 			return
 		}
+		authenticateJWT(c, strings.TrimPrefix(authHeader, "Bearer "))
+	}
+}
+
+// authenticateJWT validates an access token and passes its claims on.
+func authenticateJWT(c *gin.Context, tokenString string) {
+	claims, err := utils.ParseToken(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
 
-		// Pass claims to the context
-		c.Set("claims", claims)
-		c.Next()
+	// Reject tokens issued before the user's last credential change
+	var user models.User
+	if err := utils.DB.Select("id", "token_version", "status").Where("id = ?", claims.UserID).First(&user).Error; err != nil || user.TokenVersion != claims.TokenVersion {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
 	}
+
+	// Suspended and banned users cannot use tokens issued before the change
+	if user.Status != models.UserStatusActive {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is " + user.Status})
+		c.Abort()
+		return
+	}
+
+	// Pass claims to the context
+	c.Set("claims", claims)
+	c.Next()
 }
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Require allows the request through only if the authenticated user's role
+// holds every listed permission. It must run after JWTAuthMiddleware.
+func Require(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+		userClaims, ok := claims.(*utils.Claims)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			if !utils.HasPermission(userClaims.Role, permission) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
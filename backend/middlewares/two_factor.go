@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireTwoFactor blocks roles listed in TWO_FACTOR_REQUIRED_ROLES unless the
+// session was opened with a second factor. It must run after
+// JWTAuthMiddleware and guards the endpoints that move money.
+func RequireTwoFactor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+		userClaims, ok := claims.(*utils.Claims)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+
+		if utils.TwoFactorRequired(userClaims.Role) && !userClaims.TwoFactor {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":               "Two-factor authentication required",
+				"two_factor_required": true,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
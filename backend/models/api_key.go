@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey lets another system call the API on behalf of its owner, limited to
+// the key's scopes. Only the SHA-256 hash of the key is stored; the prefix is
+// kept in clear so a key can be looked up and recognised.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Name       string     `gorm:"type:varchar(100);not null"`
+	Prefix     string     `gorm:"type:varchar(16);not null;uniqueIndex"`
+	KeyHash    string     `gorm:"type:varchar(64);not null" json:"-"`
+	Scopes     string     `gorm:"type:text;not null"` // Comma-separated
+	ExpiresAt  *time.Time `gorm:"type:timestamp"`     // Nullable for keys that never expire
+	LastUsedAt *time.Time `gorm:"type:timestamp"`
+	RevokedAt  *time.Time `gorm:"type:timestamp"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+func (APIKey) TableName() string {
+	return "apikeys"
+}
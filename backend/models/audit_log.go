@@ -0,0 +1,65 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog records one privileged change. Entries are append-only and
+// chained: each hash covers the entry and the hash of the entry before it,
+// so editing or removing an entry breaks every hash after it.
+type AuditLog struct {
+	ID           uint64     `gorm:"primaryKey;autoIncrement"`
+	ActorID      *uuid.UUID `gorm:"type:uuid;index"` // Nullable for changes made by the system
+	ActorRole    string     `gorm:"type:varchar(50)"`
+	Action       string     `gorm:"type:varchar(255);not null;index"` // e.g. "PUT /donations/:id"
+	ResourceType string     `gorm:"type:varchar(50);not null;index:idx_auditlogs_resource"`
+	ResourceID   string     `gorm:"type:varchar(255);index:idx_auditlogs_resource"`
+	Before       string     `gorm:"type:text"` // JSON of the changed fields before the change
+	After        string     `gorm:"type:text"` // JSON of the changed fields after the change
+	IP           string     `gorm:"type:varchar(64)"`
+	RequestID    string     `gorm:"type:varchar(64)"`
+	CreatedAt    time.Time  `gorm:"type:timestamp;not null"` // UTC, set before hashing
+	PrevHash     string     `gorm:"type:varchar(64)"`
+	Hash         string     `gorm:"type:varchar(64);not null"`
+}
+
+func (AuditLog) TableName() string {
+	return "auditlogs"
+}
+
+// ComputeHash returns the chain hash of the entry, which covers every field
+// except the ID and the hash itself.
+func (a AuditLog) ComputeHash() string {
+	actorID := ""
+	if a.ActorID != nil {
+		actorID = a.ActorID.String()
+	}
+	fields := []string{
+		a.PrevHash,
+		actorID,
+		a.ActorRole,
+		a.Action,
+		a.ResourceType,
+		a.ResourceID,
+		a.Before,
+		a.After,
+		a.IP,
+		a.RequestID,
+		a.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	// Length-prefix each field so that values cannot be shifted between fields
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(strconv.Itoa(len(f)))
+		b.WriteByte(':')
+		b.WriteString(f)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -4,19 +4,81 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// Campaign statuses. A campaign is written as a draft, submitted for review
+// and goes live once a reviewer approves it:
+//
+//	draft -> pending_review -> active -> completed / closed
+//	                        -> rejected -> draft / pending_review
+//
+// Only active campaigns take donations or show up in public listings.
+const (
+	CampaignStatusDraft         = "draft"
+	CampaignStatusPendingReview = "pending_review"
+	CampaignStatusActive        = "active"
+	CampaignStatusRejected      = "rejected"
+	CampaignStatusCompleted     = "completed"
+	CampaignStatusClosed        = "closed"
+	// CampaignStatusPaused marks a campaign that stopped taking donations, for
+	// example because its creator was banned.
+	CampaignStatusPaused = "paused"
+)
+
+// campaignTransitions lists the statuses each status can move to.
+var campaignTransitions = map[string][]string{
+	CampaignStatusDraft:         {CampaignStatusPendingReview},
+	CampaignStatusPendingReview: {CampaignStatusActive, CampaignStatusRejected, CampaignStatusDraft},
+	CampaignStatusRejected:      {CampaignStatusDraft, CampaignStatusPendingReview},
+	CampaignStatusActive:        {CampaignStatusCompleted, CampaignStatusClosed, CampaignStatusPaused},
+	CampaignStatusPaused:        {CampaignStatusActive, CampaignStatusClosed},
+}
+
+// CanTransitionCampaign reports whether a campaign may move from one status to another.
+func CanTransitionCampaign(from, to string) bool {
+	for _, next := range campaignTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Campaign struct {
-	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	CreatorID     uuid.UUID `gorm:"type:uuid;not null"`
-	Title         string    `gorm:"type:varchar(255);not null"`
-	Description   string    `gorm:"type:text;not null"`
-	TargetAmount  float64   `gorm:"type:numeric(12,2);not null"`
-	CurrentAmount float64   `gorm:"type:numeric(12,2);default:0"`
-	Deadline      time.Time `gorm:"type:timestamp;not null"`
-	Status        string    `gorm:"type:varchar(50);default:'pending'"` // pending, active, completed
-	Currency      string    `gorm:"type:varchar(10);not null"`
-	Category      string    `gorm:"type:varchar(100);not null"`
-	CreatedAt     time.Time `gorm:"autoCreateTime"`
-	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+	ID              uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CreatorID       uuid.UUID      `gorm:"type:uuid;not null"`
+	Title           string         `gorm:"type:varchar(255);not null"`
+	Slug            *string        `gorm:"type:varchar(80);uniqueIndex"` // Made from Title on create; see CampaignSlugRedirect
+	Description     string         `gorm:"type:text;not null"`
+	TargetAmount    float64        `gorm:"type:numeric(12,2);not null"`
+	CurrentAmount   float64        `gorm:"type:numeric(12,2);default:0"`
+	Deadline        time.Time      `gorm:"type:timestamp;not null"`
+	Status          string         `gorm:"type:varchar(50);default:'draft'"` // See the CampaignStatus constants
+	Currency        string         `gorm:"type:varchar(10);not null"`
+	Category        string         `gorm:"type:varchar(100);not null"`
+	ApprovedBy      *uuid.UUID     `gorm:"type:uuid"`      // Reviewer who approved the campaign
+	ApprovedAt      *time.Time     `gorm:"type:timestamp"` // Nullable until approved
+	RejectedBy      *uuid.UUID     `gorm:"type:uuid"`
+	RejectedAt      *time.Time     `gorm:"type:timestamp"`
+	RejectionReason string         `gorm:"type:text"` // Shown to the creator; cleared on approval
+	CreatedAt       time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+	// SearchVector is maintained by Postgres for full-text search. Title
+	// matches weigh more than category matches, which weigh more than
+	// description matches.
+	SearchVector string `gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(category, '')), 'B') || setweight(to_tsvector('english', coalesce(description, '')), 'C')) STORED;index:idx_campaigns_search,type:gin" json:"-"`
+}
+
+// CampaignSlugRedirect is a slug a campaign had before. Old slugs keep
+// leading to the campaign and are never given to another one.
+type CampaignSlugRedirect struct {
+	Slug       string    `gorm:"type:varchar(80);primaryKey"`
+	CampaignID uuid.UUID `gorm:"type:uuid;not null;index"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (CampaignSlugRedirect) TableName() string {
+	return "campaignslugredirects"
 }
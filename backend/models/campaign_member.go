@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Campaign team roles. The campaign's creator is always an owner, whether or
+// not they have a membership row.
+const (
+	CampaignRoleOwner   = "owner"
+	CampaignRoleEditor  = "editor"
+	CampaignRoleFinance = "finance"
+	CampaignRoleViewer  = "viewer"
+)
+
+// What campaign team roles allow:
+//
+//	view:    see the campaign before it is live and its donors-only updates
+//	edit:    change its content, media, updates, milestones and rewards
+//	finance: see and request its withdrawals
+//	manage:  change its status, delete it and run its team
+const (
+	CampaignCanView    = "view"
+	CampaignCanEdit    = "edit"
+	CampaignCanFinance = "finance"
+	CampaignCanManage  = "manage"
+)
+
+// campaignRoleCapabilities lists what each team role allows.
+var campaignRoleCapabilities = map[string][]string{
+	CampaignRoleOwner:   {CampaignCanView, CampaignCanEdit, CampaignCanFinance, CampaignCanManage},
+	CampaignRoleEditor:  {CampaignCanView, CampaignCanEdit},
+	CampaignRoleFinance: {CampaignCanView, CampaignCanFinance},
+	CampaignRoleViewer:  {CampaignCanView},
+}
+
+// ValidCampaignRole reports whether role is a campaign team role.
+func ValidCampaignRole(role string) bool {
+	_, ok := campaignRoleCapabilities[role]
+	return ok
+}
+
+// CampaignRoleCan reports whether a campaign team role allows a capability.
+func CampaignRoleCan(role, capability string) bool {
+	for _, allowed := range campaignRoleCapabilities[role] {
+		if allowed == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// CampaignRolesWith returns the team roles that allow a capability.
+func CampaignRolesWith(capability string) []string {
+	var roles []string
+	for _, role := range []string{CampaignRoleOwner, CampaignRoleEditor, CampaignRoleFinance, CampaignRoleViewer} {
+		if CampaignRoleCan(role, capability) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// CampaignMember is a user on a campaign's team.
+type CampaignMember struct {
+	CampaignID uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID  `gorm:"type:uuid;primaryKey;index"`
+	Role       string     `gorm:"type:varchar(20);not null"`
+	InvitedBy  *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (CampaignMember) TableName() string {
+	return "campaignmembers"
+}
+
+// CampaignInvitation asks someone, by email, to join a campaign's team. It
+// is accepted through a single-use, expiring link. Only the SHA-256 hash of
+// the token is stored.
+type CampaignInvitation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CampaignID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Email      string     `gorm:"type:varchar(255);not null"`
+	Role       string     `gorm:"type:varchar(20);not null"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	InvitedBy  uuid.UUID  `gorm:"type:uuid;not null"`
+	ExpiresAt  time.Time  `gorm:"type:timestamp;not null"`
+	AcceptedAt *time.Time `gorm:"type:timestamp"` // Nullable until accepted
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+func (CampaignInvitation) TableName() string {
+	return "campaigninvitations"
+}
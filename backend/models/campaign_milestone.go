@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CampaignMilestone is an amount a campaign aims to raise on its way to, or
+// beyond, its target. It is marked reached by the donation that crosses it.
+type CampaignMilestone struct {
+	ID          uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CampaignID  uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_campaignmilestones_amount,where:deleted_at IS NULL"`
+	Amount      float64        `gorm:"type:numeric(12,2);not null;uniqueIndex:idx_campaignmilestones_amount,where:deleted_at IS NULL"`
+	Title       string         `gorm:"type:varchar(255);not null"`
+	Description string         `gorm:"type:text"`
+	ReachedAt   *time.Time     `gorm:"type:timestamp"` // Nullable until the campaign raises Amount
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (CampaignMilestone) TableName() string {
+	return "campaignmilestones"
+}
+
+// CampaignFollower is a user who gets notified of a campaign's milestones.
+// Donors follow the campaigns they donate to.
+type CampaignFollower struct {
+	CampaignID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (CampaignFollower) TableName() string {
+	return "campaignfollowers"
+}
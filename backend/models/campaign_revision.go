@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CampaignRevision records one edit of a campaign's content: who made it and
+// how each changed field moved. A campaign's first revision holds the values
+// it was created with.
+type CampaignRevision struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CampaignID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	EditorID   uuid.UUID  `gorm:"type:uuid;not null"`
+	Changes    string     `gorm:"type:text;not null"` // JSON object of field name to {"from": ..., "to": ...}
+	RevertedTo *uuid.UUID `gorm:"type:uuid"`          // Set when an admin reverted the campaign to an earlier revision
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+func (CampaignRevision) TableName() string {
+	return "campaignrevisions"
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Campaign update visibilities. Donors-only updates are shown to the
+// campaign's donors, its creator and moderators.
+const (
+	UpdateVisibilityPublic = "public"
+	UpdateVisibilityDonors = "donors"
+)
+
+// CampaignUpdate is news a creator posts on their campaign after it went
+// live. Posting one notifies the campaign's donors.
+type CampaignUpdate struct {
+	ID         uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CampaignID uuid.UUID      `gorm:"type:uuid;not null;index"`
+	AuthorID   uuid.UUID      `gorm:"type:uuid;not null"`
+	Title      string         `gorm:"type:varchar(255);not null"`
+	Body       string         `gorm:"type:text;not null"`
+	Visibility string         `gorm:"type:varchar(20);not null;default:'public'"` // public, donors
+	Media      []MediaFile    `gorm:"many2many:campaignupdatemedia;"`             // Media files of the same campaign
+	CreatedAt  time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+func (CampaignUpdate) TableName() string {
+	return "campaignupdates"
+}
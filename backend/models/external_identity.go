@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalIdentity links a user to an account at an OpenID Connect provider.
+// A provider's subject identifies the account for good, even if its email
+// address changes.
+type ExternalIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Provider    string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_externalidentities_provider_subject"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_externalidentities_provider_subject"`
+	Email       string     `gorm:"type:varchar(255)"` // Email asserted by the provider when the link was made
+	LastLoginAt *time.Time `gorm:"type:timestamp"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+func (ExternalIdentity) TableName() string {
+	return "externalidentities"
+}
+
+// OIDCLoginState remembers a sign-in that was sent to an identity provider
+// until the provider redirects back. It is deleted when redeemed.
+type OIDCLoginState struct {
+	StateHash    string    `gorm:"type:varchar(64);primaryKey"`
+	Provider     string    `gorm:"type:varchar(50);not null"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `gorm:"type:timestamp;not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidcloginstates"
+}
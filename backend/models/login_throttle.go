@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LoginThrottle counts recent failed logins for one account or one client IP
+// so that backoff and lockouts survive a restart.
+type LoginThrottle struct {
+	Key           string     `gorm:"type:varchar(320);primaryKey"` // "account:<email>" or "ip:<address>"
+	Failures      int        `gorm:"not null;default:0"`
+	LastFailureAt time.Time  `gorm:"type:timestamp;not null"`
+	LockedUntil   *time.Time `gorm:"type:timestamp"` // No attempts are accepted before this time
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (LoginThrottle) TableName() string {
+	return "loginthrottles"
+}
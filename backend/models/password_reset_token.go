@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken is a single-use, expiring token emailed to a user who
+// has forgotten their password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"type:timestamp;not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp"` // Nullable until redeemed
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "passwordresettokens"
+}
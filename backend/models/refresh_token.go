@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is an opaque, long-lived token that can be exchanged for a new
+// access token. Tokens are rotated on every use; all tokens descending from
+// the same login share a FamilyID so a replayed token can revoke the chain.
+type RefreshToken struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index"`
+	FamilyID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	TokenHash    string     `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt    time.Time  `gorm:"type:timestamp;not null"`
+	RevokedAt    *time.Time `gorm:"type:timestamp"`         // Set when rotated or logged out
+	ReplacedByID *uuid.UUID `gorm:"type:uuid"`              // The token issued when this one was rotated
+	TwoFactor    bool       `gorm:"not null;default:false"` // The login completed a second factor
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refreshtokens"
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reward claim fulfilment statuses:
+//
+//	pending -> shipped -> delivered
+//	        -> cancelled
+//
+// Cancelling a claim gives its reward back to the tier's stock.
+const (
+	RewardClaimPending   = "pending"
+	RewardClaimShipped   = "shipped"
+	RewardClaimDelivered = "delivered"
+	RewardClaimCancelled = "cancelled"
+)
+
+// rewardClaimTransitions lists the statuses each claim status can move to.
+var rewardClaimTransitions = map[string][]string{
+	RewardClaimPending: {RewardClaimShipped, RewardClaimCancelled},
+	RewardClaimShipped: {RewardClaimDelivered},
+}
+
+// CanTransitionRewardClaim reports whether a reward claim may move from one
+// status to another.
+func CanTransitionRewardClaim(from, to string) bool {
+	for _, next := range rewardClaimTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// RewardTier is a perk donors of a campaign can pick when they give at least
+// MinimumAmount, in the campaign's currency.
+type RewardTier struct {
+	ID                uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CampaignID        uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Title             string         `gorm:"type:varchar(255);not null"`
+	Description       string         `gorm:"type:text"`
+	MinimumAmount     float64        `gorm:"type:numeric(12,2);not null"`
+	QuantityLimit     *int           // Nullable for an unlimited reward
+	QuantityClaimed   int            `gorm:"not null;default:0"`
+	EstimatedDelivery *time.Time     `gorm:"type:timestamp"`
+	RequiresShipping  bool           `gorm:"default:false"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+}
+
+func (RewardTier) TableName() string {
+	return "rewardtiers"
+}
+
+// RewardClaim is the reward a donor picked with a donation, with the contact
+// and shipping details the creator needs to fulfil it.
+type RewardClaim struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	DonationID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex"`
+	RewardTierID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	CampaignID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	DonorID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ContactEmail   string     `gorm:"type:varchar(255);not null"`
+	ContactPhone   string     `gorm:"type:varchar(50)"`
+	ShippingName   string     `gorm:"type:varchar(255)"`
+	AddressLine1   string     `gorm:"type:varchar(255)"`
+	AddressLine2   string     `gorm:"type:varchar(255)"`
+	City           string     `gorm:"type:varchar(100)"`
+	Region         string     `gorm:"type:varchar(100)"`
+	PostalCode     string     `gorm:"type:varchar(20)"`
+	Country        string     `gorm:"type:varchar(100)"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'pending'"`
+	TrackingNumber string     `gorm:"type:varchar(255)"`
+	ShippedAt      *time.Time `gorm:"type:timestamp"`
+	DeliveredAt    *time.Time `gorm:"type:timestamp"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+
+	RewardTier RewardTier `gorm:"foreignKey:RewardTierID;references:ID"`
+	Donation   Donation   `gorm:"foreignKey:DonationID;references:ID" json:"-"` // Only loaded for the CSV export
+}
+
+func (RewardClaim) TableName() string {
+	return "rewardclaims"
+}
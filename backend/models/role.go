@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Role is a named set of permissions. Users reference a role by name through
+// User.Role; built-in roles are marked IsSystem and cannot be deleted.
+type Role struct {
+	Name        string           `gorm:"type:varchar(50);primaryKey"`
+	Description string           `gorm:"type:text"`
+	IsSystem    bool             `gorm:"not null;default:false"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleName;references:Name;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RolePermission grants a single permission (e.g. "withdrawal:approve") to a role.
+type RolePermission struct {
+	RoleName   string `gorm:"type:varchar(50);primaryKey"`
+	Permission string `gorm:"type:varchar(100);primaryKey"`
+}
+
+func (RolePermission) TableName() string {
+	return "rolepermissions"
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type SupportTicket struct {
@@ -17,6 +18,7 @@ type SupportTicket struct {
 	Answer     string    `gorm:"type:text"`  // New field for the answer
 	CreatedAt  time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
 	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
 
 }
 
@@ -6,13 +6,34 @@ import (
 	"github.com/google/uuid"
 )
 
+// Account statuses. Only active users can log in or use their tokens.
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusBanned    = "banned"
+	UserStatusErased    = "erased" // Personal data removed; the row stays for financial records
+)
+
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Email        string    `gorm:"type:varchar(255);not null;unique"`
-	PasswordHash string    `gorm:"type:varchar(255);not null"`
-	FullName     string    `gorm:"type:varchar(255);not null"`
-	Role         string    `gorm:"type:varchar(50);not null"`
-	Status       string    `gorm:"type:varchar(50);default:'active'"`
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Email             string     `gorm:"type:varchar(255);not null;unique"`
+	PasswordHash      string     `gorm:"type:varchar(255);not null" json:"-"`
+	FullName          string     `gorm:"type:varchar(255);not null"`
+	Role              string     `gorm:"type:varchar(50);not null"`
+	Status            string     `gorm:"type:varchar(50);default:'active'"`
+	StatusReason      string     `gorm:"type:text" json:"-"` // Why an admin last changed the status
+	StatusChangedAt   *time.Time `gorm:"type:timestamp"`     // Nullable until an admin changes the status
+	StatusChangedBy   *uuid.UUID `gorm:"type:uuid" json:"-"`
+	ErasedAt          *time.Time `gorm:"type:timestamp"`     // Nullable until the user's personal data is erased
+	TokenVersion      int        `gorm:"not null;default:0"` // Bumped to invalidate every issued JWT
+	EmailVerified     bool       `gorm:"not null;default:false"`
+	EmailVerifiedAt   *time.Time `gorm:"type:timestamp"`            // Nullable until verified
+	TOTPSecret        string     `gorm:"type:varchar(64)" json:"-"` // Base32; pending until TOTPEnabled is set
+	TOTPEnabled       bool       `gorm:"not null;default:false"`
+	TOTPEnabledAt     *time.Time `gorm:"type:timestamp"`
+	TOTPLastCounter   int64      `gorm:"not null;default:0" json:"-"` // Last accepted time step, so a code cannot be replayed
+	TOTPRecoveryCodes string     `gorm:"type:text" json:"-"`          // Comma-separated hashes of unused recovery codes
+	UpdatesOptOut     bool       `gorm:"not null;default:false"`      // No notifications or emails about updates on campaigns they donated to
+	CreatedAt         time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,266 @@
+// Package pagination pages list endpoints with keyset cursors. A cursor
+// records the sort values of the last row returned, so the next page starts
+// right after it no matter how many rows were inserted in between. Cursors
+// are signed, so clients can only hand back cursors the server issued.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 100
+)
+
+// ErrInvalidCursor is returned for a cursor that was tampered with, or that
+// was issued for another endpoint or sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Key is a column the rows are sorted by. Column must be a column of the
+// model, never user input.
+type Key struct {
+	Column string
+	Desc   bool
+}
+
+// Page is one request's paging options.
+type Page struct {
+	resource     string
+	keys         []Key
+	limit        int
+	after        []json.RawMessage
+	includeTotal bool
+}
+
+// Result is what a page adds to the response.
+type Result struct {
+	NextCursor *string
+	Total      *int64
+}
+
+// FromRequest reads the limit, cursor and include_total query parameters.
+// Rows are sorted by keys and then by id, which keeps the order total.
+// resource names the endpoint, so its cursors are not accepted elsewhere.
+func FromRequest(c *gin.Context, resource string, keys ...Key) (*Page, error) {
+	page := &Page{resource: resource, limit: DefaultLimit}
+	page.keys = append(append(page.keys, keys...), Key{Column: "id", Desc: len(keys) > 0 && keys[len(keys)-1].Desc})
+
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+		}
+		page.limit = n
+	}
+	page.includeTotal = c.Query("include_total") == "true"
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err := page.decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		page.after = after
+	}
+	return page, nil
+}
+
+// Find loads the page of rows matched by query into dest.
+func Find[T any](page *Page, query *gorm.DB, dest *[]T) (Result, error) {
+	var result Result
+	if query.Statement.Model == nil {
+		query = query.Model(new(T))
+	}
+	query = query.Session(&gorm.Session{})
+
+	if page.includeTotal {
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return result, err
+		}
+		result.Total = &total
+	}
+
+	stmt := &gorm.Statement{DB: query, Context: query.Statement.Context}
+	if err := stmt.Parse(new(T)); err != nil {
+		return result, err
+	}
+
+	paged := query
+	if page.after != nil {
+		condition, err := page.afterCondition(stmt, page.after)
+		if err != nil {
+			return result, err
+		}
+		paged = paged.Where(condition)
+	}
+	for _, key := range page.keys {
+		paged = paged.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: key.Column}, Desc: key.Desc})
+	}
+	// Fetch one extra row to learn whether there is a next page
+	if err := paged.Limit(page.limit + 1).Find(dest).Error; err != nil {
+		return result, err
+	}
+
+	if len(*dest) > page.limit {
+		*dest = (*dest)[:page.limit]
+		cursor, err := page.encode(stmt, reflect.ValueOf(&(*dest)[page.limit-1]).Elem())
+		if err != nil {
+			return result, err
+		}
+		result.NextCursor = &cursor
+	}
+	return result, nil
+}
+
+// Apply adds next_cursor, and total when it was asked for, to a response.
+func (r Result) Apply(response gin.H) gin.H {
+	response["next_cursor"] = r.NextCursor
+	if r.Total != nil {
+		response["total"] = *r.Total
+	}
+	return response
+}
+
+// afterCondition matches the rows that sort after the given key values:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with < for descending keys.
+func (p *Page) afterCondition(stmt *gorm.Statement, after []json.RawMessage) (clause.Expression, error) {
+	values := make([]interface{}, len(p.keys))
+	for i, key := range p.keys {
+		field := stmt.Schema.LookUpField(key.Column)
+		if field == nil {
+			return nil, fmt.Errorf("pagination: unknown column %q", key.Column)
+		}
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(after[i], value.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = value.Elem().Interface()
+	}
+
+	var or []clause.Expression
+	for i, key := range p.keys {
+		var and []clause.Expression
+		for j := 0; j < i; j++ {
+			and = append(and, clause.Eq{Column: p.column(j), Value: values[j]})
+		}
+		if key.Desc {
+			and = append(and, clause.Lt{Column: p.column(i), Value: values[i]})
+		} else {
+			and = append(and, clause.Gt{Column: p.column(i), Value: values[i]})
+		}
+		or = append(or, clause.And(and...))
+	}
+	return clause.Or(or...), nil
+}
+
+func (p *Page) column(i int) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: p.keys[i].Column}
+}
+
+type cursorPayload struct {
+	Resource string            `json:"r"`
+	Order    string            `json:"o"`
+	After    []json.RawMessage `json:"a"`
+}
+
+// order describes the sort order, so a cursor cannot be reused with another.
+func (p *Page) order() string {
+	parts := make([]string, len(p.keys))
+	for i, key := range p.keys {
+		parts[i] = key.Column
+		if key.Desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (p *Page) encode(stmt *gorm.Statement, row reflect.Value) (string, error) {
+	payload := cursorPayload{Resource: p.resource, Order: p.order()}
+	for _, key := range p.keys {
+		field := stmt.Schema.LookUpField(key.Column)
+		if field == nil {
+			return "", fmt.Errorf("pagination: unknown column %q", key.Column)
+		}
+		value, _ := field.ValueOf(stmt.Context, row)
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		payload.After = append(payload.After, data)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(encoded)), nil
+}
+
+func (p *Page) decode(cursor string) ([]json.RawMessage, error) {
+	encoded, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, sign(encoded)) {
+		return nil, ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if payload.Resource != p.resource || payload.Order != p.order() || len(payload.After) != len(p.keys) {
+		return nil, ErrInvalidCursor
+	}
+	return payload.After, nil
+}
+
+var (
+	cursorSecret     []byte
+	cursorSecretOnce sync.Once
+)
+
+// InitCursorSecret loads the key cursors are signed with from CURSOR_SECRET,
+// or JWT_SECRET when it is not set, and exits when neither is set. Every
+// replica must share it to accept the others' cursors.
+func InitCursorSecret() {
+	cursorSecretOnce.Do(func() {
+		secret := utils.GetEnv("CURSOR_SECRET", os.Getenv("JWT_SECRET"))
+		if secret == "" {
+			log.Fatal("No cursor secret configured: set CURSOR_SECRET or JWT_SECRET")
+		}
+		cursorSecret = []byte(secret)
+	})
+}
+
+// sign returns the HMAC of a cursor.
+func sign(encoded string) []byte {
+	InitCursorSecret()
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write([]byte("cursor:" + encoded))
+	return mac.Sum(nil)
+}
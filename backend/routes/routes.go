@@ -3,6 +3,7 @@ package routes
 import (
 	"backend/controllers"
 	"backend/middlewares"
+	"backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,18 +12,36 @@ func SetupRouter() *gin.Engine {
 	r := gin.Default()
 
 	r.Use(middlewares.MetricsMiddleware())
+	r.Use(middlewares.RequestID())
 
 	// Public routes
 	r.POST("/register", controllers.RegisterUser)
 	r.POST("/login", controllers.LoginUser)
+	r.POST("/password/forgot", controllers.ForgotPassword)            // Email a one-time reset link
+	r.POST("/password/reset", controllers.ResetPassword)              // Redeem a reset token
+	r.POST("/email/verify", controllers.VerifyEmail)                  // Confirm an email address from the signed link
+	r.POST("/account/claim/request", controllers.RequestAccountClaim) // Email a claim link to a guest donor
+	r.POST("/account/claim", controllers.ClaimAccount)                // Set a password on a guest donor account
+	r.POST("/refresh-token", controllers.RefreshToken)                // Rotate a refresh token for a new access token
+	r.POST("/login/2fa", controllers.VerifyLoginChallenge)            // Finish a login with a TOTP or recovery code
+
+	// Sign in with an OpenID Connect identity provider
+	r.GET("/auth/oidc/providers", controllers.ListOIDCProviders)
+	r.GET("/auth/oidc/:provider/login", controllers.StartOIDCLogin)        // Get the provider's authorization URL
+	r.POST("/auth/oidc/:provider/callback", controllers.CompleteOIDCLogin) // Redeem the code the provider redirected back with
+
+	// Public keys for services that verify Impacta tokens
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS)
 
 	// Campaigns (Public Access)
-	r.GET("/campaigns", controllers.ListCampaigns)          // List all campaigns
-	r.GET("/campaigns/detail/:id", controllers.GetCampaign) // Get a single campaign (updated URL)
+	r.GET("/campaigns", middlewares.OptionalJWTAuth(), controllers.ListCampaigns)          // List active campaigns, plus your own when logged in
+	r.GET("/campaigns/search", middlewares.OptionalJWTAuth(), controllers.SearchCampaigns) // Full-text search with ranking and facets
+	r.GET("/campaigns/detail/:id", middlewares.OptionalJWTAuth(), controllers.GetCampaign) // Get a single campaign (updated URL)
+	r.GET("/campaigns/detail/:id/revisions", middlewares.OptionalJWTAuth(), controllers.ListCampaignRevisions)
 
 	// Donations (Public Access)
 	r.POST("/donations", controllers.MakeDonation) // Make a donation
-	r.GET("/campaigns/detail/:id/donations", controllers.ListCampaignDonations)
+	r.GET("/campaigns/detail/:id/donations", middlewares.OptionalJWTAuth(), controllers.ListCampaignDonations)
 
 	// Media Files (Public Access)
 	r.GET("/campaigns/:campaign_id/mediafiles", controllers.ListMediaFilesByCampaignID)
@@ -34,63 +53,159 @@ func SetupRouter() *gin.Engine {
 	r.GET("/campaigns/:campaign_id/comments", controllers.ListCommentsByCampaignID)
 	r.GET("/users/:user_id/comments", controllers.ListCommentsByUserID)
 
+	// Campaign updates (Public Access; donors-only updates need a donor's token)
+	r.GET("/campaigns/:campaign_id/updates", middlewares.OptionalJWTAuth(), controllers.ListCampaignUpdates)
+	r.GET("/campaigns/:campaign_id/updates/:update_id", middlewares.OptionalJWTAuth(), controllers.GetCampaignUpdate)
+	r.POST("/campaign-updates/opt-out", controllers.OptOutOfUpdates) // Unsubscribe link from an update email
+	r.GET("/campaigns/:campaign_id/milestones", middlewares.OptionalJWTAuth(), controllers.ListCampaignMilestones)
+	r.GET("/campaigns/:campaign_id/rewards", middlewares.OptionalJWTAuth(), controllers.ListRewardTiers)
+
+	// Read routes for integrations: they take a user's access token or an API
+	// key with the matching scope
+	donationsRead := middlewares.APIKeyOrJWTAuth(utils.ScopeDonationsRead)
+	paymentsRead := middlewares.APIKeyOrJWTAuth(utils.ScopePaymentsRead)
+	withdrawalsRead := middlewares.APIKeyOrJWTAuth(utils.ScopeWithdrawalsRead)
+	r.GET("/user/donations", donationsRead, middlewares.Require(utils.PermDonationRead), controllers.ListUserDonations) // List donations across campaigns
+	r.GET("/paymenttransactions/:id", paymentsRead, controllers.GetPaymentTransactionByID)                              // Get by ID
+	r.GET("/paymenttransactions", paymentsRead, controllers.ListPaymentTransactions)                                    // List transactions, optional filter by donation_id
+	r.GET("/withdrawals/:id", withdrawalsRead, controllers.GetWithdrawalByID)                                           // Get Withdrawal by ID
+	r.GET("/withdrawals", withdrawalsRead, controllers.ListWithdrawals)                                                 // List Withdrawals (optional filter by campaign_id)
+
 	// Protected routes
 	protected := r.Group("/")
 	protected.Use(middlewares.JWTAuthMiddleware())
 
-	protected.POST("/refresh-token", controllers.RefreshToken) // Refresh JWT token
+	protected.POST("/email/resend-verification", controllers.ResendVerificationEmail) // Resend the verification link
+
+	// Sessions
+	protected.POST("/logout", controllers.Logout)        // Revoke a refresh token
+	protected.POST("/logout-all", controllers.LogoutAll) // Sign out of every session
+
+	// API keys for integrations
+	protected.GET("/api-keys/scopes", controllers.ListAPIKeyScopes)
+	protected.GET("/api-keys", controllers.ListAPIKeys)
+	protected.POST("/api-keys", controllers.CreateAPIKey) // The key is only shown in this response
+	protected.DELETE("/api-keys/:id", controllers.RevokeAPIKey)
+
+	// Two-factor authentication
+	protected.POST("/2fa/enroll", controllers.EnrollTwoFactor)                 // Generate a TOTP secret
+	protected.POST("/2fa/confirm", controllers.ConfirmTwoFactor)               // Turn 2FA on with a first code
+	protected.POST("/2fa/disable", controllers.DisableTwoFactor)               // Turn 2FA off
+	protected.POST("/2fa/recovery-codes", controllers.RegenerateRecoveryCodes) // Replace the recovery codes
+
+	// Endpoints that move money need a session opened with a second factor
+	// for the roles in TWO_FACTOR_REQUIRED_ROLES
+	twoFactor := middlewares.RequireTwoFactor()
 
 	// Users
-	protected.GET("/user", controllers.GetUser)       // Get user details
-	protected.PUT("/user", controllers.UpdateUser)    // Update user
-	protected.DELETE("/user", controllers.DeleteUser) // Delete user
-	protected.GET("/users", controllers.GetAllUsers)  // Only for admin
+	protected.GET("/user", controllers.GetUser)               // Get user details
+	protected.PUT("/user", controllers.UpdateUser)            // Update user
+	protected.DELETE("/user", controllers.DeleteUser)         // Erase the user's personal data
+	protected.GET("/user/export", controllers.ExportUserData) // Download the user's personal data
+	protected.GET("/users", middlewares.Require(utils.PermUserRead), controllers.GetAllUsers)
+
+	// Routes shared by users and moderators write the moderators' changes
+	// to the audit log
+	moderated := func(resourceType string) gin.HandlerFunc {
+		return middlewares.AuditLogFor(resourceType, utils.PermCampaignModerate)
+	}
 
 	// Campaigns (Protected Access for creation, updates, and deletion)
-	protected.POST("/campaigns", controllers.CreateCampaign)              // Create a campaign
-	protected.PUT("/campaigns/detail/:id", controllers.UpdateCampaign)    // Update a campaign
-	protected.DELETE("/campaigns/detail/:id", controllers.DeleteCampaign) // Delete a campaign
+	protected.POST("/campaigns", middlewares.Require(utils.PermCampaignCreate), controllers.CreateCampaign) // Create a campaign
+	protected.PUT("/campaigns/detail/:id", moderated("campaign"), controllers.UpdateCampaign)               // Update a campaign
+	protected.DELETE("/campaigns/detail/:id", moderated("campaign"), controllers.DeleteCampaign)            // Delete a campaign
+	protected.POST("/campaigns/detail/:id/submit", controllers.SubmitCampaign)                              // Submit a draft for review
+
+	// Campaign updates: the creator or a moderator posts them
+	protected.POST("/campaigns/:campaign_id/updates", moderated("campaign_update"), controllers.CreateCampaignUpdate) // Notifies the campaign's donors
+	protected.PUT("/campaigns/:campaign_id/updates/:update_id", moderated("campaign_update"), controllers.UpdateCampaignUpdate)
+	protected.DELETE("/campaigns/:campaign_id/updates/:update_id", moderated("campaign_update"), controllers.DeleteCampaignUpdate)
+	protected.POST("/campaigns/:campaign_id/milestones", moderated("campaign_milestone"), controllers.CreateCampaignMilestone)
+	protected.PUT("/campaigns/:campaign_id/milestones/:milestone_id", moderated("campaign_milestone"), controllers.UpdateCampaignMilestone)
+	protected.DELETE("/campaigns/:campaign_id/milestones/:milestone_id", moderated("campaign_milestone"), controllers.DeleteCampaignMilestone)
+	protected.POST("/campaigns/:campaign_id/follow", controllers.FollowCampaign)
+	protected.DELETE("/campaigns/:campaign_id/follow", controllers.UnfollowCampaign)
+	protected.POST("/campaigns/:campaign_id/rewards", moderated("reward_tier"), controllers.CreateRewardTier)
+	protected.PUT("/campaigns/:campaign_id/rewards/:reward_id", moderated("reward_tier"), controllers.UpdateRewardTier)
+	protected.DELETE("/campaigns/:campaign_id/rewards/:reward_id", moderated("reward_tier"), controllers.DeleteRewardTier)
+	protected.GET("/campaigns/:campaign_id/reward-claims", controllers.ListRewardClaims) // Creator's fulfilment list
+	protected.GET("/campaigns/:campaign_id/reward-claims/export", controllers.ExportRewardClaims)
+	protected.PUT("/campaigns/:campaign_id/reward-claims/:claim_id", moderated("reward_claim"), controllers.UpdateRewardClaim)
+	protected.GET("/campaigns/:campaign_id/members", controllers.ListCampaignMembers)
+	protected.PUT("/campaigns/:campaign_id/members/:user_id", moderated("campaign_member"), controllers.UpdateCampaignMember)
+	protected.DELETE("/campaigns/:campaign_id/members/:user_id", moderated("campaign_member"), controllers.RemoveCampaignMember) // Owners, or members leaving
+	protected.POST("/campaigns/:campaign_id/invitations", moderated("campaign_invitation"), controllers.InviteCampaignMember)    // Emails an invitation link
+	protected.DELETE("/campaigns/:campaign_id/invitations/:invitation_id", moderated("campaign_invitation"), controllers.RevokeCampaignInvitation)
+	protected.POST("/campaign-invitations/accept", controllers.AcceptCampaignInvitation)
 
 	// Donations (Protected)
-	protected.GET("/user/donations", controllers.ListUserDonations) // List user's donations
-	protected.PUT("/donations/:id", controllers.UpdateDonation)     // Admin-only route to update donation
+	protected.PUT("/donations/:id", middlewares.Require(utils.PermDonationUpdate), twoFactor, middlewares.AuditLog("donation"), controllers.UpdateDonation)
 
 	// MediaFiles Protected routes: creation and bulk deletion
-	protected.POST("/mediafiles", controllers.CreateMediaFile)
-	protected.DELETE("/mediafiles/bulk", controllers.BulkDeleteMediaFiles)
+	protected.POST("/mediafiles", middlewares.Require(utils.PermMediaCreate), controllers.CreateMediaFile)
+	protected.DELETE("/mediafiles/bulk", moderated("media_file"), controllers.BulkDeleteMediaFiles)
 
 	// Comments Protected routes: creation, update and deletion
-	protected.PUT("/comments/:id", controllers.UpdateComment)
-	protected.DELETE("/comments/:id", controllers.DeleteComment)
+	auditComment := middlewares.AuditLogFor("comment", utils.PermCommentModerate)
+	protected.PUT("/comments/:id", auditComment, controllers.UpdateComment)
+	protected.DELETE("/comments/:id", auditComment, controllers.DeleteComment)
 	protected.POST("/comments", controllers.CreateComment)
 
 	// Notifications Protected routes
 	protected.POST("/notifications", controllers.CreateNotification)
 	protected.GET("/notifications/:id", controllers.GetNotificationByID)
 	protected.GET("/notifications", controllers.ListNotificationsByUser)
-	protected.PUT("/notifications/:id", controllers.UpdateNotificationByID)
-	protected.DELETE("/notifications/bulk", controllers.BulkDeleteNotifications)
+	auditNotification := middlewares.AuditLogFor("notification", utils.PermNotificationManage)
+	protected.PUT("/notifications/:id", auditNotification, controllers.UpdateNotificationByID)
+	protected.DELETE("/notifications/bulk", auditNotification, controllers.BulkDeleteNotifications)
 
 	// Support Tickets Protected routes
 	protected.POST("/support-tickets", controllers.CreateSupportTicket)
 	protected.GET("/support-tickets/:id", controllers.GetSupportTicketByID)
 	protected.GET("/support-tickets", controllers.ListSupportTickets)
-	protected.PUT("/support-tickets/:id", controllers.UpdateSupportTicketByID)
-	protected.DELETE("/support-tickets/bulk", controllers.BulkDeleteSupportTickets)
-
-	// Payment Transactions Protected routes (admin-only for update and bulk deletion)
-	protected.POST("/paymenttransactions", controllers.CreatePaymentTransaction)             // Create Payment Transaction
-	protected.GET("/paymenttransactions/:id", controllers.GetPaymentTransactionByID)         // Get by ID
-	protected.GET("/paymenttransactions", controllers.ListPaymentTransactions)               // List transactions, optional filter by donation_id
-	protected.PUT("/paymenttransactions/:id", controllers.UpdatePaymentTransaction)          // Admin-only update
-	protected.DELETE("/paymenttransactions/bulk", controllers.BulkDeletePaymentTransactions) // Admin-only bulk delete
-
-	// Withdrawals Protected routes (admin-only for update and bulk deletion)
-	protected.POST("/withdrawals", controllers.CreateWithdrawal)             // Create a Withdrawal
-	protected.GET("/withdrawals/:id", controllers.GetWithdrawalByID)         // Get Withdrawal by ID
-	protected.GET("/withdrawals", controllers.ListWithdrawals)               // List Withdrawals (optional filter by campaign_id)
-	protected.PUT("/withdrawals/:id", controllers.UpdateWithdrawal)          // Admin-only update
-	protected.DELETE("/withdrawals/bulk", controllers.BulkDeleteWithdrawals) // Admin-only bulk delete
+	auditTicket := middlewares.AuditLogFor("support_ticket", utils.PermSupportManage)
+	protected.PUT("/support-tickets/:id", auditTicket, controllers.UpdateSupportTicketByID)
+	protected.DELETE("/support-tickets/bulk", auditTicket, controllers.BulkDeleteSupportTickets)
+
+	// Payment Transactions Protected routes
+	protected.POST("/paymenttransactions", middlewares.Require(utils.PermPaymentCreate), twoFactor, middlewares.AuditLog("payment_transaction"), controllers.CreatePaymentTransaction) // Create Payment Transaction
+	protected.PUT("/paymenttransactions/:id", middlewares.Require(utils.PermPaymentManage), twoFactor, middlewares.AuditLog("payment_transaction"), controllers.UpdatePaymentTransaction)
+	protected.DELETE("/paymenttransactions/bulk", middlewares.Require(utils.PermPaymentManage), twoFactor, middlewares.AuditLog("payment_transaction"), controllers.BulkDeletePaymentTransactions)
+
+	// Withdrawals Protected routes
+	protected.POST("/withdrawals", twoFactor, middlewares.AuditLog("withdrawal"), controllers.CreateWithdrawal) // Create a Withdrawal (the campaign's finance team, checked by the handler)
+	protected.PUT("/withdrawals/:id", middlewares.Require(utils.PermWithdrawalApprove), twoFactor, middlewares.AuditLog("withdrawal"), controllers.UpdateWithdrawal)
+	protected.DELETE("/withdrawals/bulk", middlewares.Require(utils.PermWithdrawalDelete), twoFactor, middlewares.AuditLog("withdrawal"), controllers.BulkDeleteWithdrawals)
+
+	// Administration. Every change made here is written to the audit log
+	admin := protected.Group("/admin")
+	roles := middlewares.Require(utils.PermRoleManage)
+	auditRole := middlewares.AuditLog("role")
+	admin.GET("/permissions", roles, controllers.ListPermissions)
+	admin.GET("/roles", roles, controllers.ListRoles)
+	admin.POST("/roles", roles, auditRole, controllers.CreateRole)
+	admin.PUT("/roles/:name", roles, auditRole, controllers.UpdateRole)
+	admin.DELETE("/roles/:name", roles, auditRole, controllers.DeleteRole)
+	users := middlewares.Require(utils.PermUserManage)
+	auditUser := middlewares.AuditLog("user")
+	admin.PUT("/users/:id/role", roles, auditUser, controllers.AssignUserRole)
+	admin.POST("/users/:id/unlock", users, auditUser, controllers.UnlockUser) // Clear failed login attempts
+	admin.POST("/users/:id/suspend", users, auditUser, controllers.SuspendUser)
+	admin.POST("/users/:id/ban", users, auditUser, controllers.BanUser)
+	admin.POST("/users/:id/reactivate", users, auditUser, controllers.ReactivateUser)
+	admin.POST("/users/:id/erase", users, auditUser, controllers.EraseUser) // Erase personal data on the user's behalf
+	review := middlewares.Require(utils.PermCampaignReview)
+	auditCampaign := middlewares.AuditLog("campaign")
+	admin.POST("/campaigns/:id/approve", review, auditCampaign, controllers.ApproveCampaign)
+	admin.POST("/campaigns/:id/reject", review, auditCampaign, controllers.RejectCampaign)
+	admin.POST("/campaigns/:id/revisions/:revision_id/revert", middlewares.Require(utils.PermCampaignModerate), auditCampaign, controllers.RevertCampaignRevision)
+	// Soft-deleted records. Each resource needs the permission that lets
+	// its records be deleted or moderated
+	admin.GET("/deleted/:resource", controllers.ListDeletedRecords)
+	admin.POST("/deleted/:resource/:id/restore", middlewares.AuditLog("deleted_record"), controllers.RestoreDeletedRecord)
+	audit := middlewares.Require(utils.PermAuditRead)
+	admin.GET("/audit-log", audit, controllers.ListAuditLog)
+	admin.GET("/audit-log/verify", audit, controllers.VerifyAuditLog) // Check the hash chain for tampering
 
 	return r
 }
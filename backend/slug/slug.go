@@ -0,0 +1,96 @@
+// Package slug turns titles into URL slugs: lowercase ASCII words joined by
+// hyphens, e.g. "Café für Kinder" becomes "cafe-fur-kinder".
+package slug
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MinLength and MaxLength bound the slugs Valid accepts. Make cuts
+	// longer titles at a word boundary.
+	MinLength = 3
+	MaxLength = 80
+
+	// Fallback is used for titles with nothing to transliterate.
+	Fallback = "campaign"
+)
+
+var valid = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// transliterations covers letters that do not decompose into an ASCII letter
+// and a combining mark, including Cyrillic and Greek.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Make returns a valid slug for title, or Fallback when nothing in it can be
+// transliterated.
+func Make(title string) string {
+	// Split accented letters into letter and mark, then drop the marks
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), title)
+	if err != nil {
+		stripped = title
+	}
+
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(stripped) {
+		if s, ok := transliterations[r]; ok {
+			if s != "" {
+				b.WriteString(s)
+				hyphen = false
+			}
+			continue
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	s := strings.Trim(b.String(), "-")
+	if len(s) > MaxLength {
+		s = s[:MaxLength]
+		if i := strings.LastIndexByte(s, '-'); i >= MinLength {
+			s = s[:i]
+		}
+		s = strings.Trim(s, "-")
+	}
+	if len(s) < MinLength {
+		return Fallback
+	}
+	if _, err := uuid.Parse(s); err == nil {
+		return Fallback + "-" + s
+	}
+	return s
+}
+
+// Valid reports whether s can be used as a slug. Slugs that look like UUIDs
+// are refused, since campaigns are looked up by either.
+func Valid(s string) bool {
+	if len(s) < MinLength || len(s) > MaxLength || !valid.MatchString(s) {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err != nil
+}
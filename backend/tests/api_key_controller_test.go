@@ -0,0 +1,146 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"backend/controllers"
+	"backend/middlewares"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupAPIKeyTestDB connects to the test PostgreSQL database and migrates the User
+// and APIKey models.
+func setupAPIKeyTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.APIKey{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+// sendAPIKey calls path with the key in the X-API-Key header.
+func sendAPIKey(router *gin.Engine, path, key string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-API-Key", key)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// TestGenerateAPIKey_Prefix ensures generated keys can be looked up by their prefix.
+func TestGenerateAPIKey_Prefix(t *testing.T) {
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if !utils.IsAPIKey(key) || hash != utils.HashToken(key) {
+		t.Errorf("unexpected key %q or hash", key)
+	}
+	if got, ok := utils.APIKeyPrefix(key); !ok || got != prefix {
+		t.Errorf("expected prefix %q, got %q (%v)", prefix, got, ok)
+	}
+	if _, ok := utils.APIKeyPrefix("imp_short_secret"); ok {
+		t.Error("expected a malformed key to be rejected")
+	}
+}
+
+// TestAPIKey_ScopesAndRevocation creates a key, uses it on a route that accepts its
+// scope, checks a route needing another scope refuses it, and revokes it.
+func TestAPIKey_ScopesAndRevocation(t *testing.T) {
+	db := setupAPIKeyTestDB(t)
+	uid := uuid.New()
+	owner := models.User{
+		ID:       uid,
+		Email:    "apikey-" + uid.String() + "@example.com",
+		FullName: "Integration Owner",
+		Role:     utils.RoleCampaignCreator,
+		Status:   models.UserStatusActive,
+	}
+	if err := db.Create(&owner).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") == "" {
+			c.Set("claims", &utils.Claims{UserID: owner.ID.String(), Role: owner.Role})
+		}
+		c.Next()
+	})
+	router.POST("/api-keys", controllers.CreateAPIKey)
+	router.DELETE("/api-keys/:id", controllers.RevokeAPIKey)
+	whoami := func(c *gin.Context) {
+		claims := c.MustGet("claims").(*utils.Claims)
+		c.JSON(http.StatusOK, gin.H{"user_id": claims.UserID})
+	}
+	router.GET("/donations", middlewares.APIKeyOrJWTAuth(utils.ScopeDonationsRead), whoami)
+	router.GET("/withdrawals", middlewares.APIKeyOrJWTAuth(utils.ScopeWithdrawalsRead), whoami)
+
+	rr := sendRoleJSON(router, http.MethodPost, "/api-keys", map[string]interface{}{"name": "Bad", "scopes": []string{"users:delete"}})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown scope, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = sendRoleJSON(router, http.MethodPost, "/api-keys", map[string]interface{}{
+		"name":   "Accounting export",
+		"scopes": []string{utils.ScopeDonationsRead},
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	var created struct {
+		Key    string `json:"key"`
+		APIKey struct {
+			ID string `json:"id"`
+		} `json:"api_key"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &created)
+
+	var stored models.APIKey
+	db.First(&stored, "id = ?", created.APIKey.ID)
+	if stored.KeyHash == created.Key || stored.KeyHash != utils.HashToken(created.Key) {
+		t.Error("expected only the key hash to be stored")
+	}
+
+	rr = sendAPIKey(router, "/donations", created.Key)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	db.First(&stored, "id = ?", created.APIKey.ID)
+	if stored.LastUsedAt == nil {
+		t.Error("expected last_used_at to be recorded")
+	}
+
+	if rr := sendAPIKey(router, "/withdrawals", created.Key); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d without the scope, got %d", http.StatusForbidden, rr.Code)
+	}
+
+	rr = sendRoleJSON(router, http.MethodDelete, "/api-keys/"+created.APIKey.ID, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr := sendAPIKey(router, "/donations", created.Key); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d for a revoked key, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
@@ -0,0 +1,224 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/middlewares"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupAuditTestDB connects to the test PostgreSQL database and migrates the User,
+// Campaign, Withdrawal and AuditLog models.
+func setupAuditTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.Withdrawal{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+// TestAuditDiff_ChangedFieldsOnly ensures unchanged fields are left out of the diff.
+func TestAuditDiff_ChangedFieldsOnly(t *testing.T) {
+	before := map[string]interface{}{"Status": "pending", "Amount": 100}
+	after := map[string]interface{}{"Status": "processed", "Amount": 100}
+
+	b, a, err := utils.AuditDiff(before, after)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b != `{"Status":"pending"}` || a != `{"Status":"processed"}` {
+		t.Errorf("unexpected diff %s -> %s", b, a)
+	}
+
+	b, a, _ = utils.AuditDiff(before, nil)
+	if b != `{"Amount":100,"Status":"pending"}` || a != "" {
+		t.Errorf("expected the full record for a deletion, got %s -> %s", b, a)
+	}
+}
+
+// TestAuditLog_ComputeHash ensures any edit to an entry changes its hash.
+func TestAuditLog_ComputeHash(t *testing.T) {
+	entry := models.AuditLog{
+		Action:       "PUT /withdrawals/:id",
+		ResourceType: "withdrawal",
+		ResourceID:   uuid.NewString(),
+		After:        `{"Status":"processed"}`,
+		CreatedAt:    time.Now().UTC(),
+		PrevHash:     "abc",
+	}
+	hash := entry.ComputeHash()
+
+	entry.After = `{"Status":"failed"}`
+	if entry.ComputeHash() == hash {
+		t.Error("expected the hash to change with the entry")
+	}
+}
+
+// TestAuditLog_RecordsWithdrawalUpdate ensures an admin update is written to the
+// chain with its before and after values.
+func TestAuditLog_RecordsWithdrawalUpdate(t *testing.T) {
+	db := setupAuditTestDB(t)
+
+	creator := models.User{ID: uuid.New(), FullName: "Audit Creator", Role: utils.RoleCampaignCreator, Status: models.UserStatusActive}
+	creator.Email = "audit-" + creator.ID.String() + "@example.com"
+	if err := db.Create(&creator).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creator.ID,
+		Title:        "Audit Campaign",
+		Description:  "Campaign for audit tests",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(24 * time.Hour),
+		Status:       "active",
+		Currency:     "USD",
+		Category:     "Test",
+	}
+	withdrawal := models.Withdrawal{ID: uuid.New(), CampaignID: campaign.ID, Amount: 250, Status: "pending"}
+	for _, record := range []interface{}{&campaign, &withdrawal} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("failed to create test record: %v", err)
+		}
+	}
+
+	router := roleTestRouter(utils.RoleAdmin)
+	router.Use(middlewares.RequestID())
+	router.PUT("/withdrawals/:id", middlewares.AuditLog("withdrawal"), controllers.UpdateWithdrawal)
+	router.GET("/admin/audit-log", controllers.ListAuditLog)
+	router.GET("/admin/audit-log/verify", controllers.VerifyAuditLog)
+
+	rr := sendRoleJSON(router, http.MethodPut, "/withdrawals/"+withdrawal.ID.String(), map[string]string{"status": "processed"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	requestID := rr.Header().Get("X-Request-ID")
+
+	rr = sendRoleJSON(router, http.MethodGet, "/admin/audit-log?resource_type=withdrawal&resource_id="+withdrawal.ID.String(), nil)
+	var list struct {
+		Entries []struct {
+			Action    string                 `json:"action"`
+			Before    map[string]interface{} `json:"before"`
+			After     map[string]interface{} `json:"after"`
+			RequestID string                 `json:"request_id"`
+		} `json:"entries"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d. Response: %s", len(list.Entries), rr.Body.String())
+	}
+	entry := list.Entries[0]
+	if entry.Action != "PUT /withdrawals/:id" || entry.RequestID != requestID {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.Before["Status"] != "pending" || entry.After["Status"] != "processed" {
+		t.Errorf("expected the status change in the diff, got %v -> %v", entry.Before, entry.After)
+	}
+	if _, ok := entry.After["Amount"]; ok {
+		t.Error("expected unchanged fields to be left out")
+	}
+
+	rr = sendRoleJSON(router, http.MethodGet, "/admin/audit-log/verify", nil)
+	var verify map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &verify)
+	if verify["valid"] != true {
+		t.Errorf("expected an intact chain, got %v", verify)
+	}
+}
+
+// TestAuditLogFor_RecordsModerators ensures a route shared by users and
+// moderators only records the moderators' changes.
+func TestAuditLogFor_RecordsModerators(t *testing.T) {
+	db := setupAuditTestDB(t)
+	if err := db.AutoMigrate(&models.Comment{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+	author := createReviewTestUser(t, db, utils.RoleDonor)
+	admin := createReviewTestUser(t, db, utils.RoleAdmin)
+
+	router := reviewTestRouter()
+	router.DELETE("/comments/:id", middlewares.AuditLogFor("comment", utils.PermCommentModerate), controllers.DeleteComment)
+
+	for _, tc := range []struct {
+		user    models.User
+		entries int64
+	}{
+		{author, 0},
+		{admin, 1},
+	} {
+		comment := models.Comment{CampaignID: uuid.New(), UserID: author.ID, Content: "Audited comment"}
+		if err := db.Create(&comment).Error; err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+		rr := sendReviewJSON(router, tc.user, http.MethodDelete, "/comments/"+comment.ID.String(), nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var entries int64
+		db.Model(&models.AuditLog{}).Where("resource_type = ? AND resource_id = ?", "comment", comment.ID.String()).Count(&entries)
+		if entries != tc.entries {
+			t.Errorf("%s: expected %d audit entries, got %d", tc.user.Role, tc.entries, entries)
+		}
+	}
+}
+
+// TestAuditLog_RecordsRolePermissions ensures a change to the permissions a
+// role grants is logged with the permissions before and after.
+func TestAuditLog_RecordsRolePermissions(t *testing.T) {
+	db := setupAuditTestDB(t)
+	if err := db.AutoMigrate(&models.Role{}, &models.RolePermission{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	router := roleTestRouter(utils.RoleAdmin)
+	router.POST("/admin/roles", middlewares.AuditLog("role"), controllers.CreateRole)
+	router.PUT("/admin/roles/:name", middlewares.AuditLog("role"), controllers.UpdateRole)
+
+	name := "audit_" + uuid.NewString()[:8]
+	rr := sendRoleJSON(router, http.MethodPost, "/admin/roles", map[string]interface{}{
+		"name": name, "permissions": []string{utils.PermCommentModerate},
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	rr = sendRoleJSON(router, http.MethodPut, "/admin/roles/"+name, map[string]interface{}{
+		"permissions": []string{utils.PermUserRead},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var entry models.AuditLog
+	if err := db.Where("resource_type = ? AND resource_id = ? AND action LIKE ?", "role", name, "PUT %").First(&entry).Error; err != nil {
+		t.Fatalf("expected the update to be logged: %v", err)
+	}
+	var before, after struct {
+		Permissions []string `json:"permissions"`
+	}
+	json.Unmarshal([]byte(entry.Before), &before)
+	json.Unmarshal([]byte(entry.After), &after)
+	if len(before.Permissions) != 1 || before.Permissions[0] != utils.PermCommentModerate ||
+		len(after.Permissions) != 1 || after.Permissions[0] != utils.PermUserRead {
+		t.Errorf("expected the permission change in the diff, got %s -> %s", entry.Before, entry.After)
+	}
+}
@@ -31,8 +31,10 @@ func setupCampaignTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("failed to connect to database: %v", err)
 	}
 
-	// Migrate both User and Campaign models.
-	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}); err != nil {
+	// Migrate User and Campaign, the milestones shown with a campaign, the
+	// team members allowed to manage it, its old slugs and its revisions.
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.CampaignMilestone{},
+		&models.CampaignMember{}, &models.CampaignSlugRedirect{}, &models.CampaignRevision{}); err != nil {
 		t.Fatalf("failed to migrate models: %v", err)
 	}
 
@@ -263,7 +265,7 @@ func TestListCampaigns(t *testing.T) {
 			Deadline:      time.Now().Add(time.Duration(24*i) * time.Hour),
 			Currency:      "USD",
 			Category:      "category" + strconv.Itoa(i),
-			Status:        "pending",
+			Status:        "active",
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
 		}
@@ -310,7 +312,7 @@ func TestGetCampaign(t *testing.T) {
 		Deadline:      time.Now().Add(72 * time.Hour),
 		Currency:      "USD",
 		Category:      "tech",
-		Status:        "pending",
+		Status:        "active",
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
@@ -0,0 +1,153 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/jobs"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupLifecycleTestDB connects to the test PostgreSQL database and migrates
+// the models the campaign lifecycle job touches.
+func setupLifecycleTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.Donation{}, &models.Notification{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+// createLifecycleTestCampaign creates a campaign with the given status, amounts and deadline.
+func createLifecycleTestCampaign(t *testing.T, db *gorm.DB, creatorID uuid.UUID, status string, current float64, deadline time.Time) models.Campaign {
+	campaign := models.Campaign{
+		ID:            uuid.New(),
+		CreatorID:     creatorID,
+		Title:         "Lifecycle " + status,
+		Description:   "Lifecycle test campaign",
+		TargetAmount:  1000,
+		CurrentAmount: current,
+		Deadline:      deadline,
+		Status:        status,
+		Currency:      "USD",
+		Category:      "test",
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	return campaign
+}
+
+// TestRunCampaignLifecycle ensures funded campaigns are completed, expired ones
+// are closed, and everything else is left alone.
+func TestRunCampaignLifecycle(t *testing.T) {
+	db := setupLifecycleTestDB(t)
+	creatorID := createTestUser2(db, "lifecycle-"+uuid.NewString()+"@example.com", "Creator", utils.RoleCampaignCreator, "")
+
+	future := time.Now().UTC().Add(72 * time.Hour)
+	past := time.Now().UTC().Add(-time.Hour)
+	funded := createLifecycleTestCampaign(t, db, creatorID, models.CampaignStatusActive, 1000, future)
+	expired := createLifecycleTestCampaign(t, db, creatorID, models.CampaignStatusActive, 200, past)
+	expiredFunded := createLifecycleTestCampaign(t, db, creatorID, models.CampaignStatusPaused, 1500, past)
+	running := createLifecycleTestCampaign(t, db, creatorID, models.CampaignStatusActive, 200, future)
+	draft := createLifecycleTestCampaign(t, db, creatorID, models.CampaignStatusDraft, 0, past)
+
+	if err := controllers.RunCampaignLifecycle(context.Background()); err != nil {
+		t.Fatalf("lifecycle job failed: %v", err)
+	}
+
+	for campaign, want := range map[uuid.UUID]string{
+		funded.ID:        models.CampaignStatusCompleted,
+		expired.ID:       models.CampaignStatusClosed,
+		expiredFunded.ID: models.CampaignStatusCompleted,
+		running.ID:       models.CampaignStatusActive,
+		draft.ID:         models.CampaignStatusDraft,
+	} {
+		var got models.Campaign
+		db.First(&got, "id = ?", campaign)
+		if got.Status != want {
+			t.Errorf("expected campaign %q to be %s, got %s", got.Title, want, got.Status)
+		}
+	}
+
+	var summaries int64
+	db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", creatorID, "campaign_summary").Count(&summaries)
+	if summaries != 3 {
+		t.Errorf("expected 3 summaries for the creator, got %d", summaries)
+	}
+}
+
+// TestMakeDonation_PastDeadline ensures a campaign past its deadline refuses
+// money before the lifecycle job has closed it.
+func TestMakeDonation_PastDeadline(t *testing.T) {
+	db := setupLifecycleTestDB(t)
+	router := roleTestRouter(utils.RoleDonor)
+	router.POST("/donations", controllers.MakeDonation)
+
+	creatorID := createTestUser2(db, "lifecycle-"+uuid.NewString()+"@example.com", "Creator", utils.RoleCampaignCreator, "")
+	campaign := createLifecycleTestCampaign(t, db, creatorID, models.CampaignStatusActive, 0, time.Now().UTC().Add(-time.Minute))
+
+	rr := sendRoleJSON(router, http.MethodPost, "/donations", map[string]interface{}{
+		"campaign_id": campaign.ID.String(),
+		"donor_name":  "Late Donor",
+		"email":       "late-" + uuid.NewString() + "@example.com",
+		"amount":      10,
+		"currency":    "USD",
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+}
+
+// TestRunLocked_SkipsWhenLocked ensures a job does not run while another
+// replica holds its lock.
+func TestRunLocked_SkipsWhenLocked(t *testing.T) {
+	db := setupLifecycleTestDB(t)
+	ctx := context.Background()
+
+	runs := 0
+	job := jobs.Job{Name: "lock-test-" + uuid.NewString(), Interval: time.Minute, Run: func(context.Context) error {
+		runs++
+		return nil
+	}}
+
+	// Hold the lock on a separate connection, as another replica would
+	sqlDB, _ := db.DB()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to open connection: %v", err)
+	}
+	defer conn.Close()
+	conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", jobs.LockKey(job.Name))
+
+	if ran, err := jobs.RunLocked(ctx, job); err != nil || ran {
+		t.Errorf("expected the job to be skipped, got ran=%v err=%v", ran, err)
+	}
+
+	conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", jobs.LockKey(job.Name))
+	if ran, err := jobs.RunLocked(ctx, job); err != nil || !ran {
+		t.Errorf("expected the job to run, got ran=%v err=%v", ran, err)
+	}
+	if runs != 1 {
+		t.Errorf("expected 1 run, got %d", runs)
+	}
+}
@@ -0,0 +1,234 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/routes"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupCampaignMemberTestDB connects to the test PostgreSQL database and
+// migrates the models campaign teams touch.
+func setupCampaignMemberTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.Notification{}, &models.Withdrawal{},
+		&models.CampaignMember{}, &models.CampaignInvitation{}, &models.CampaignRevision{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+func campaignMemberTestRouter() *gin.Engine {
+	router := reviewTestRouter()
+	router.DELETE("/campaigns/detail/:id", controllers.DeleteCampaign)
+	router.GET("/campaigns/:campaign_id/members", controllers.ListCampaignMembers)
+	router.PUT("/campaigns/:campaign_id/members/:user_id", controllers.UpdateCampaignMember)
+	router.DELETE("/campaigns/:campaign_id/members/:user_id", controllers.RemoveCampaignMember)
+	router.POST("/campaigns/:campaign_id/invitations", controllers.InviteCampaignMember)
+	router.POST("/campaign-invitations/accept", controllers.AcceptCampaignInvitation)
+	router.POST("/withdrawals", controllers.CreateWithdrawal)
+	return router
+}
+
+func createTeamTestCampaign(t *testing.T, db *gorm.DB, creator models.User) models.Campaign {
+	campaign := models.Campaign{
+		ID: uuid.New(), CreatorID: creator.ID, Title: "Team campaign", Description: "d",
+		TargetAmount: 1000, Deadline: time.Now().Add(72 * time.Hour), Status: models.CampaignStatusActive,
+		Currency: "USD", Category: "test",
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	return campaign
+}
+
+// createTestInvitation stores an invitation and returns its raw token, which
+// is otherwise only emailed.
+func createTestInvitation(t *testing.T, db *gorm.DB, campaign models.Campaign, email, role string) string {
+	raw, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	invitation := models.CampaignInvitation{
+		ID: uuid.New(), CampaignID: campaign.ID, Email: email, Role: role, TokenHash: hash,
+		InvitedBy: campaign.CreatorID, ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := db.Create(&invitation).Error; err != nil {
+		t.Fatalf("failed to create invitation: %v", err)
+	}
+	return raw
+}
+
+// TestCampaignMembers_InviteAndAccept invites a user and checks only that
+// user can redeem the invitation, once.
+func TestCampaignMembers_InviteAndAccept(t *testing.T) {
+	db := setupCampaignMemberTestDB(t)
+	router := campaignMemberTestRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	invitee := createReviewTestUser(t, db, utils.RoleDonor)
+	other := createReviewTestUser(t, db, utils.RoleDonor)
+	campaign := createTeamTestCampaign(t, db, creator)
+	base := "/campaigns/" + campaign.ID.String()
+
+	payload := map[string]interface{}{"email": invitee.Email, "role": "editor"}
+	if rr := sendReviewJSON(router, other, http.MethodPost, base+"/invitations", payload); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for a stranger but got %d", http.StatusForbidden, rr.Code)
+	}
+	if rr := sendReviewJSON(router, creator, http.MethodPost, base+"/invitations", map[string]interface{}{"email": invitee.Email, "role": "boss"}); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown role but got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := sendReviewJSON(router, creator, http.MethodPost, base+"/invitations", payload); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	var notifications int64
+	db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", invitee.ID, "campaign_invitation").Count(&notifications)
+	if notifications != 1 {
+		t.Errorf("expected the invitee to be notified, got %d notifications", notifications)
+	}
+
+	token := createTestInvitation(t, db, campaign, invitee.Email, models.CampaignRoleEditor)
+	accept := map[string]interface{}{"token": token}
+	if rr := sendReviewJSON(router, other, http.MethodPost, "/campaign-invitations/accept", accept); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for another user but got %d", http.StatusForbidden, rr.Code)
+	}
+	if rr := sendReviewJSON(router, invitee, http.MethodPost, "/campaign-invitations/accept", accept); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr := sendReviewJSON(router, invitee, http.MethodPost, "/campaign-invitations/accept", accept); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d accepting twice but got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr := sendReviewJSON(router, invitee, http.MethodGet, base+"/members", nil)
+	var list struct {
+		Members     []map[string]interface{} `json:"members"`
+		Invitations []interface{}            `json:"invitations"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Members) != 2 || list.Members[0]["role"] != "owner" || list.Members[1]["role"] != "editor" {
+		t.Errorf("expected the creator and an editor, got %s", rr.Body.String())
+	}
+	if list.Invitations != nil {
+		t.Error("expected invitations to be hidden from editors")
+	}
+	if rr := sendReviewJSON(router, other, http.MethodGet, base+"/members", nil); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for a stranger but got %d", http.StatusForbidden, rr.Code)
+	}
+
+	creatorPath := base + "/members/" + creator.ID.String()
+	if rr := sendReviewJSON(router, creator, http.MethodDelete, creatorPath, nil); rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d removing the creator but got %d", http.StatusConflict, rr.Code)
+	}
+	// Members can leave on their own
+	if rr := sendReviewJSON(router, invitee, http.MethodDelete, base+"/members/"+invitee.ID.String(), nil); rr.Code != http.StatusOK {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
+
+// TestCampaignMembers_FinanceWithdrawsThroughRouter requests a withdrawal
+// over the real routes as a finance member whose own role is donor, which
+// has no withdrawal permission of its own.
+func TestCampaignMembers_FinanceWithdrawsThroughRouter(t *testing.T) {
+	db := setupCampaignMemberTestDB(t)
+	gin.SetMode(gin.TestMode)
+	router := routes.SetupRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	campaign := createTeamTestCampaign(t, db, creator)
+	finance := createReviewTestUser(t, db, utils.RoleDonor)
+	db.Create(&models.CampaignMember{CampaignID: campaign.ID, UserID: finance.ID, Role: models.CampaignRoleFinance})
+	stranger := createReviewTestUser(t, db, utils.RoleDonor)
+
+	withdraw := func(user models.User) *httptest.ResponseRecorder {
+		token, err := utils.GenerateToken(user.ID.String(), user.Email, user.Role, user.TokenVersion)
+		if err != nil {
+			t.Fatalf("failed to generate token: %v", err)
+		}
+		body, _ := json.Marshal(map[string]interface{}{"campaign_id": campaign.ID, "amount": 10})
+		req, _ := http.NewRequest(http.MethodPost, "/withdrawals", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := withdraw(finance); rr.Code != http.StatusCreated {
+		t.Errorf("expected status %d for a finance member but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	if rr := withdraw(stranger); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for a donor outside the team but got %d", http.StatusForbidden, rr.Code)
+	}
+}
+
+// TestCampaignMembers_RolesLimitActions checks what each team role can do to
+// the campaign and its money.
+func TestCampaignMembers_RolesLimitActions(t *testing.T) {
+	db := setupCampaignMemberTestDB(t)
+	router := campaignMemberTestRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	campaign := createTeamTestCampaign(t, db, creator)
+	members := map[string]models.User{}
+	for _, role := range []string{models.CampaignRoleOwner, models.CampaignRoleEditor, models.CampaignRoleFinance, models.CampaignRoleViewer} {
+		user := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+		db.Create(&models.CampaignMember{CampaignID: campaign.ID, UserID: user.ID, Role: role})
+		members[role] = user
+	}
+	detail := "/campaigns/detail/" + campaign.ID.String()
+
+	for _, tc := range []struct {
+		role                          string
+		edit, changeStatus, withdrawn int
+	}{
+		{models.CampaignRoleEditor, http.StatusOK, http.StatusForbidden, http.StatusForbidden},
+		{models.CampaignRoleFinance, http.StatusForbidden, http.StatusForbidden, http.StatusCreated},
+		{models.CampaignRoleViewer, http.StatusForbidden, http.StatusForbidden, http.StatusForbidden},
+		{models.CampaignRoleOwner, http.StatusOK, http.StatusOK, http.StatusCreated},
+	} {
+		user := members[tc.role]
+		if rr := sendReviewJSON(router, user, http.MethodPut, detail, map[string]interface{}{"title": "Renamed by " + tc.role}); rr.Code != tc.edit {
+			t.Errorf("%s: expected status %d editing but got %d", tc.role, tc.edit, rr.Code)
+		}
+		if rr := sendReviewJSON(router, user, http.MethodPut, detail, map[string]interface{}{"status": "paused"}); rr.Code != tc.changeStatus {
+			t.Errorf("%s: expected status %d pausing but got %d", tc.role, tc.changeStatus, rr.Code)
+		}
+		if tc.changeStatus == http.StatusOK {
+			db.Model(&campaign).Update("status", models.CampaignStatusActive)
+		}
+		rr := sendReviewJSON(router, user, http.MethodPost, "/withdrawals", map[string]interface{}{"campaign_id": campaign.ID, "amount": 10})
+		if rr.Code != tc.withdrawn {
+			t.Errorf("%s: expected status %d requesting a withdrawal but got %d", tc.role, tc.withdrawn, rr.Code)
+		}
+	}
+
+	if rr := sendReviewJSON(router, members[models.CampaignRoleEditor], http.MethodDelete, detail, nil); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for an editor deleting but got %d", http.StatusForbidden, rr.Code)
+	}
+	if rr := sendReviewJSON(router, members[models.CampaignRoleOwner], http.MethodDelete, detail, nil); rr.Code != http.StatusOK {
+		t.Errorf("expected status %d for a co-owner deleting but got %d", http.StatusOK, rr.Code)
+	}
+}
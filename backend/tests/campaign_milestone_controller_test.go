@@ -0,0 +1,182 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupCampaignMilestoneTestDB connects to the test PostgreSQL database and
+// migrates the models milestones touch.
+func setupCampaignMilestoneTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.Donation{}, &models.Notification{},
+		&models.CampaignMilestone{}, &models.CampaignFollower{}, &models.CampaignMember{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+func campaignMilestoneTestRouter() *gin.Engine {
+	router := reviewTestRouter()
+	router.POST("/donations", controllers.MakeDonation)
+	router.GET("/campaigns/detail/:id", controllers.GetCampaign)
+	router.POST("/campaigns/:campaign_id/milestones", controllers.CreateCampaignMilestone)
+	router.PUT("/campaigns/:campaign_id/milestones/:milestone_id", controllers.UpdateCampaignMilestone)
+	router.POST("/campaigns/:campaign_id/follow", controllers.FollowCampaign)
+	return router
+}
+
+func milestoneNotifications(db *gorm.DB, userID uuid.UUID) int64 {
+	var count int64
+	db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", userID, "campaign_milestone").Count(&count)
+	return count
+}
+
+// TestCampaignMilestones_ReachedByDonations donates past two milestones and
+// checks they are marked reached and the creator and followers are told.
+func TestCampaignMilestones_ReachedByDonations(t *testing.T) {
+	db := setupCampaignMilestoneTestDB(t)
+	router := campaignMilestoneTestRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	follower := createReviewTestUser(t, db, utils.RoleDonor)
+	stranger := createReviewTestUser(t, db, utils.RoleDonor)
+	campaign := models.Campaign{
+		ID: uuid.New(), CreatorID: creator.ID, Title: "Milestone campaign", Description: "d",
+		TargetAmount: 1000, Deadline: time.Now().Add(72 * time.Hour), Status: models.CampaignStatusActive,
+		Currency: "USD", Category: "test",
+	}
+	db.Create(&campaign)
+
+	path := "/campaigns/" + campaign.ID.String() + "/milestones"
+	for _, amount := range []float64{500, 100, 200} {
+		rr := sendReviewJSON(router, creator, http.MethodPost, path, map[string]interface{}{"amount": amount, "title": "Stretch"})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+		}
+	}
+	if rr := sendReviewJSON(router, creator, http.MethodPost, path, map[string]interface{}{"amount": 100, "title": "Again"}); rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d for a duplicate amount but got %d", http.StatusConflict, rr.Code)
+	}
+	if rr := sendReviewJSON(router, stranger, http.MethodPost, path, map[string]interface{}{"amount": 50, "title": "Mine"}); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for a stranger but got %d", http.StatusForbidden, rr.Code)
+	}
+	if rr := sendReviewJSON(router, follower, http.MethodPost, "/campaigns/"+campaign.ID.String()+"/follow", nil); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	donorEmail := "milestone-donor-" + uuid.NewString() + "@example.com"
+	rr := sendReviewJSON(router, models.User{}, http.MethodPost, "/donations", map[string]interface{}{
+		"campaign_id": campaign.ID, "donor_name": "Donor", "email": donorEmail, "amount": 250, "currency": "USD",
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	var donor models.User
+	db.Where("email = ?", donorEmail).First(&donor)
+
+	for _, tc := range []struct {
+		name string
+		id   uuid.UUID
+		want int64
+	}{{"creator", creator.ID, 2}, {"follower", follower.ID, 2}, {"donor", donor.ID, 2}, {"stranger", stranger.ID, 0}} {
+		if got := milestoneNotifications(db, tc.id); got != tc.want {
+			t.Errorf("%s: expected %d milestone notifications, got %d", tc.name, tc.want, got)
+		}
+	}
+
+	// Donating again does not notify the reached milestones twice
+	sendReviewJSON(router, models.User{}, http.MethodPost, "/donations", map[string]interface{}{
+		"campaign_id": campaign.ID, "donor_name": "Donor", "email": donorEmail, "amount": 10, "currency": "USD",
+	})
+	if got := milestoneNotifications(db, creator.ID); got != 2 {
+		t.Errorf("expected no new milestone notifications, got %d in total", got)
+	}
+
+	// Anonymous donors do not follow the campaign
+	anonymousEmail := "milestone-anonymous-" + uuid.NewString() + "@example.com"
+	sendReviewJSON(router, models.User{}, http.MethodPost, "/donations", map[string]interface{}{
+		"campaign_id": campaign.ID, "donor_name": "Anonymous", "email": anonymousEmail, "amount": 5, "currency": "USD", "is_anonymous": true,
+	})
+	var anonymous models.User
+	db.Where("email = ?", anonymousEmail).First(&anonymous)
+	var follows int64
+	db.Model(&models.CampaignFollower{}).Where("user_id = ?", anonymous.ID).Count(&follows)
+	if anonymous.ID == uuid.Nil || follows != 0 {
+		t.Errorf("expected the anonymous donor not to follow the campaign, got %d follows", follows)
+	}
+
+	rr = sendReviewJSON(router, models.User{}, http.MethodGet, "/campaigns/detail/"+campaign.ID.String(), nil)
+	var resp struct {
+		Milestones []models.CampaignMilestone `json:"milestones"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Milestones) != 3 {
+		t.Fatalf("expected 3 milestones, got %d. Response: %s", len(resp.Milestones), rr.Body.String())
+	}
+	for i, want := range []float64{100, 200, 500} {
+		milestone := resp.Milestones[i]
+		if milestone.Amount != want {
+			t.Errorf("milestone %d: expected amount %.0f, got %.0f", i, want, milestone.Amount)
+		}
+		if reached := milestone.ReachedAt != nil; reached != (want <= 260) {
+			t.Errorf("milestone %.0f: unexpected reached state %v", want, reached)
+		}
+	}
+
+	// A reached milestone keeps its amount
+	reachedPath := path + "/" + resp.Milestones[0].ID.String()
+	if rr := sendReviewJSON(router, creator, http.MethodPut, reachedPath, map[string]interface{}{"amount": 150}); rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d but got %d", http.StatusConflict, rr.Code)
+	}
+}
+
+// TestCampaignMilestones_AlreadyRaised adds a milestone below the amount
+// raised so far, which is reached at once without notifications.
+func TestCampaignMilestones_AlreadyRaised(t *testing.T) {
+	db := setupCampaignMilestoneTestDB(t)
+	router := campaignMilestoneTestRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	campaign := models.Campaign{
+		ID: uuid.New(), CreatorID: creator.ID, Title: "Raised campaign", Description: "d",
+		TargetAmount: 1000, CurrentAmount: 300, Deadline: time.Now().Add(72 * time.Hour), Status: models.CampaignStatusActive,
+		Currency: "USD", Category: "test",
+	}
+	db.Create(&campaign)
+
+	rr := sendReviewJSON(router, creator, http.MethodPost, "/campaigns/"+campaign.ID.String()+"/milestones",
+		map[string]interface{}{"amount": 250, "title": "Quarter"})
+	var created struct {
+		Milestone models.CampaignMilestone `json:"milestone"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if rr.Code != http.StatusCreated || created.Milestone.ReachedAt == nil {
+		t.Errorf("expected a reached milestone, got %d %s", rr.Code, rr.Body.String())
+	}
+	if got := milestoneNotifications(db, creator.ID); got != 0 {
+		t.Errorf("expected no milestone notifications, got %d", got)
+	}
+}
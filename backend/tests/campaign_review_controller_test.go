@@ -0,0 +1,232 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupCampaignReviewTestDB connects to the test PostgreSQL database and migrates the
+// User, Campaign and Notification models.
+func setupCampaignReviewTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.Notification{}, &models.CampaignMember{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+// createReviewTestUser creates an active user with the given role.
+func createReviewTestUser(t *testing.T, db *gorm.DB, role string) models.User {
+	user := models.User{ID: uuid.New(), FullName: "Review User", Role: role, Status: models.UserStatusActive}
+	user.Email = "review-" + user.ID.String() + "@example.com"
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	return user
+}
+
+// reviewTestRouter authenticates each request as the user sent by sendReviewJSON.
+func reviewTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			c.Set("claims", &utils.Claims{UserID: id, Role: c.GetHeader("X-Test-Role")})
+		}
+		c.Next()
+	})
+	router.GET("/campaigns", controllers.ListCampaigns)
+	router.PUT("/campaigns/detail/:id", controllers.UpdateCampaign)
+	router.POST("/campaigns/detail/:id/submit", controllers.SubmitCampaign)
+	router.POST("/admin/campaigns/:id/approve", controllers.ApproveCampaign)
+	router.POST("/admin/campaigns/:id/reject", controllers.RejectCampaign)
+	return router
+}
+
+// sendReviewJSON sends the request as the given user, or anonymously for a zero user.
+func sendReviewJSON(router *gin.Engine, user models.User, method, path string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user.ID != uuid.Nil {
+		req.Header.Set("X-Test-User", user.ID.String())
+		req.Header.Set("X-Test-Role", user.Role)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// listedCampaign reports whether the campaign is in the listing the user
+// sees, following next_cursor through every page.
+func listedCampaign(t *testing.T, router *gin.Engine, user models.User, id uuid.UUID) bool {
+	path := "/campaigns?limit=100"
+	for {
+		rr := sendReviewJSON(router, user, http.MethodGet, path, nil)
+		var resp struct {
+			Campaigns  []models.Campaign `json:"campaigns"`
+			NextCursor *string           `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		for _, campaign := range resp.Campaigns {
+			if campaign.ID == id {
+				return true
+			}
+		}
+		if resp.NextCursor == nil {
+			return false
+		}
+		path = "/campaigns?limit=100&cursor=" + *resp.NextCursor
+	}
+}
+
+// TestCampaignReview_ApproveFlow walks a campaign from draft to active and checks who
+// can see it along the way.
+func TestCampaignReview_ApproveFlow(t *testing.T) {
+	db := setupCampaignReviewTestDB(t)
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	admin := createReviewTestUser(t, db, utils.RoleAdmin)
+	router := reviewTestRouter()
+
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creator.ID,
+		Title:        "Review Campaign",
+		Description:  "Campaign waiting for review",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(72 * time.Hour),
+		Status:       models.CampaignStatusDraft,
+		Currency:     "USD",
+		Category:     "Test",
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create test campaign: %v", err)
+	}
+	path := "/campaigns/detail/" + campaign.ID.String()
+
+	if rr := sendReviewJSON(router, creator, http.MethodPut, path, map[string]string{"status": "active"}); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d when a creator activates a draft, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if listedCampaign(t, router, models.User{}, campaign.ID) {
+		t.Error("expected a draft to be hidden from the public")
+	}
+	if !listedCampaign(t, router, creator, campaign.ID) {
+		t.Error("expected the creator to see their draft")
+	}
+
+	rr := sendReviewJSON(router, creator, http.MethodPost, path+"/submit", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr := sendReviewJSON(router, creator, http.MethodPut, path, map[string]string{"status": "active"}); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d when a creator approves their own campaign, got %d", http.StatusForbidden, rr.Code)
+	}
+
+	rr = sendReviewJSON(router, admin, http.MethodPost, "/admin/campaigns/"+campaign.ID.String()+"/approve", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var approved models.Campaign
+	db.First(&approved, "id = ?", campaign.ID)
+	if approved.Status != models.CampaignStatusActive || approved.ApprovedBy == nil || *approved.ApprovedBy != admin.ID || approved.ApprovedAt == nil {
+		t.Errorf("expected an active campaign approved by the admin, got %+v", approved)
+	}
+	if !listedCampaign(t, router, models.User{}, campaign.ID) {
+		t.Error("expected the approved campaign to be public")
+	}
+	var notifications int64
+	db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", creator.ID, "campaign_review").Count(&notifications)
+	if notifications != 1 {
+		t.Errorf("expected the creator to be notified, got %d notifications", notifications)
+	}
+
+	rr = sendReviewJSON(router, admin, http.MethodPost, "/admin/campaigns/"+campaign.ID.String()+"/approve", nil)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d for an already approved campaign, got %d", http.StatusConflict, rr.Code)
+	}
+
+	// The creator can pause the campaign but only a moderator resumes it
+	for _, tc := range []struct {
+		user   models.User
+		status string
+		want   int
+	}{
+		{creator, "paused", http.StatusOK},
+		{creator, "active", http.StatusForbidden},
+		{admin, "active", http.StatusOK},
+	} {
+		if rr := sendReviewJSON(router, tc.user, http.MethodPut, path, map[string]string{"status": tc.status}); rr.Code != tc.want {
+			t.Errorf("%s as %s: expected status %d but got %d", tc.status, tc.user.Role, tc.want, rr.Code)
+		}
+	}
+}
+
+// TestCampaignReview_Reject ensures a rejection needs a reason and records it.
+func TestCampaignReview_Reject(t *testing.T) {
+	db := setupCampaignReviewTestDB(t)
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	admin := createReviewTestUser(t, db, utils.RoleAdmin)
+	router := reviewTestRouter()
+
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creator.ID,
+		Title:        "Rejected Campaign",
+		Description:  "Campaign that will be rejected",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(72 * time.Hour),
+		Status:       models.CampaignStatusPendingReview,
+		Currency:     "USD",
+		Category:     "Test",
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create test campaign: %v", err)
+	}
+	path := "/admin/campaigns/" + campaign.ID.String() + "/reject"
+
+	if rr := sendReviewJSON(router, admin, http.MethodPost, path, map[string]string{}); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d without a reason, got %d", http.StatusBadRequest, rr.Code)
+	}
+	rr := sendReviewJSON(router, admin, http.MethodPost, path, map[string]string{"reason": "Missing proof of identity"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var rejected models.Campaign
+	db.First(&rejected, "id = ?", campaign.ID)
+	if rejected.Status != models.CampaignStatusRejected || rejected.RejectionReason != "Missing proof of identity" || rejected.RejectedBy == nil {
+		t.Errorf("expected a rejected campaign with the reason, got %+v", rejected)
+	}
+
+	rr = sendReviewJSON(router, creator, http.MethodPost, "/campaigns/detail/"+campaign.ID.String()+"/submit", nil)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected the creator to resubmit, got %d. Response: %s", rr.Code, rr.Body.String())
+	}
+}
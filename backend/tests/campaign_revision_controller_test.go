@@ -0,0 +1,111 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type campaignRevisionResponse struct {
+	ID         uuid.UUID                  `json:"id"`
+	EditorID   uuid.UUID                  `json:"editor_id"`
+	Changes    map[string]json.RawMessage `json:"changes"`
+	RevertedTo *uuid.UUID                 `json:"reverted_to"`
+}
+
+func campaignRevisionTestRouter() *gin.Engine {
+	router := campaignSlugTestRouter()
+	router.GET("/campaigns/detail/:id/revisions", controllers.ListCampaignRevisions)
+	router.POST("/admin/campaigns/:id/revisions/:revision_id/revert", controllers.RevertCampaignRevision)
+	return router
+}
+
+func listCampaignRevisions(t *testing.T, router *gin.Engine, user models.User, id uuid.UUID) []campaignRevisionResponse {
+	rr := sendReviewJSON(router, user, http.MethodGet, "/campaigns/detail/"+id.String()+"/revisions", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Revisions []campaignRevisionResponse `json:"revisions"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	return resp.Revisions
+}
+
+// TestCampaignRevisions records a campaign's edits, checks the history and
+// reverts the campaign to the values it was created with.
+func TestCampaignRevisions(t *testing.T) {
+	db := setupCampaignSlugTestDB(t)
+	router := campaignRevisionTestRouter()
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	admin := createReviewTestUser(t, db, utils.RoleAdmin)
+
+	campaign := createSlugTestCampaign(t, router, creator, "Water Well")
+	detail := "/campaigns/detail/" + campaign.ID.String()
+	newDeadline := time.Now().Add(240 * time.Hour).UTC().Truncate(time.Second)
+
+	rr := sendReviewJSON(router, creator, http.MethodPut, detail, map[string]interface{}{"title": "Two Water Wells", "target_amount": 2000})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	rr = sendReviewJSON(router, creator, http.MethodPut, detail, map[string]interface{}{"deadline": newDeadline.Format(time.RFC3339)})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	revisions := listCampaignRevisions(t, router, creator, campaign.ID)
+	if len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revisions))
+	}
+	created, edited := revisions[2], revisions[1]
+	if len(created.Changes) != 5 || created.EditorID != creator.ID {
+		t.Errorf("expected the first revision to hold all 5 fields by the creator, got %v", created)
+	}
+	if len(edited.Changes) != 2 || string(edited.Changes["target_amount"]) != `{"from":1000,"to":2000}` {
+		t.Errorf("expected the goal change from 1000 to 2000, got %v", edited.Changes)
+	}
+	if _, ok := revisions[0].Changes["deadline"]; !ok || len(revisions[0].Changes) != 1 {
+		t.Errorf("expected only the deadline in the latest revision, got %v", revisions[0].Changes)
+	}
+	if rr := sendReviewJSON(router, creator, http.MethodGet, "/campaigns/detail/"+*campaign.Slug+"/revisions", nil); rr.Code != http.StatusOK {
+		t.Errorf("expected status %d for the history by slug but got %d", http.StatusOK, rr.Code)
+	}
+	if rr := sendReviewJSON(router, models.User{}, http.MethodGet, detail+"/revisions", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a draft's history but got %d", http.StatusNotFound, rr.Code)
+	}
+
+	revert := "/admin/campaigns/" + *campaign.Slug + "/revisions/" + created.ID.String() + "/revert"
+	rr = sendReviewJSON(router, admin, http.MethodPost, revert, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var reverted models.Campaign
+	db.Where("id = ?", campaign.ID).First(&reverted)
+	if reverted.Title != "Water Well" || reverted.TargetAmount != 1000 || !reverted.Deadline.Equal(campaign.Deadline) {
+		t.Errorf("expected the original content back, got %q, %v, %v", reverted.Title, reverted.TargetAmount, reverted.Deadline)
+	}
+
+	revisions = listCampaignRevisions(t, router, creator, campaign.ID)
+	if len(revisions) != 4 || revisions[0].RevertedTo == nil || *revisions[0].RevertedTo != created.ID || revisions[0].EditorID != admin.ID {
+		t.Errorf("expected the revert to be recorded as a revision by the admin, got %v", revisions)
+	}
+
+	if rr := sendReviewJSON(router, admin, http.MethodPost, revert, nil); rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d when nothing changes but got %d", http.StatusConflict, rr.Code)
+	}
+	other := createSlugTestCampaign(t, router, creator, "Other Campaign")
+	otherRevert := "/admin/campaigns/" + other.ID.String() + "/revisions/" + created.ID.String() + "/revert"
+	if rr := sendReviewJSON(router, admin, http.MethodPost, otherRevert, nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for another campaign's revision but got %d", http.StatusNotFound, rr.Code)
+	}
+}
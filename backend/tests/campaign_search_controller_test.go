@@ -0,0 +1,120 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TestPrefixTSQuery ensures user input always becomes valid prefix-matching tsquery syntax.
+func TestPrefixTSQuery(t *testing.T) {
+	cases := map[string]string{
+		"clean wat":          "clean:* & wat:*",
+		"  Water!! & | ':*(": "water:*",
+		"école 2025":         "école:* & 2025:*",
+		"!!! &|":             "",
+	}
+	for input, want := range cases {
+		if got := utils.PrefixTSQuery(input); got != want {
+			t.Errorf("PrefixTSQuery(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func createSearchTestCampaign(t *testing.T, db *gorm.DB, creatorID uuid.UUID, title, category, description, status string) uuid.UUID {
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creatorID,
+		Title:        title,
+		Description:  description,
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(72 * time.Hour),
+		Status:       status,
+		Currency:     "USD",
+		Category:     category,
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	return campaign.ID
+}
+
+// TestSearchCampaigns ranks title matches first, hides unpublished campaigns,
+// escapes snippets and counts facets.
+func TestSearchCampaigns(t *testing.T) {
+	db := setupCampaignTestDB(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/campaigns/search", controllers.SearchCampaigns)
+
+	creatorID := createTestUser1(db, "search@example.com", "Search Creator", utils.RoleCampaignCreator, "")
+	titleMatch := createSearchTestCampaign(t, db, creatorID, "Clean water for Kibera", "health",
+		"Drilling wells so families stop walking for hours.", models.CampaignStatusActive)
+	createSearchTestCampaign(t, db, creatorID, "Books for Nairobi schools", "education",
+		"Textbooks <script>alert(1)</script> and clean drinking water for classrooms.", models.CampaignStatusActive)
+	createSearchTestCampaign(t, db, creatorID, "Water draft", "health", "Not reviewed yet.", models.CampaignStatusDraft)
+	createSearchTestCampaign(t, db, creatorID, "Football kits", "sports", "Kits for the youth league.", models.CampaignStatusActive)
+
+	req, _ := http.NewRequest(http.MethodGet, "/campaigns/search?q=wat", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Results []struct {
+			Campaign       models.Campaign `json:"campaign"`
+			TitleHighlight string          `json:"title_highlight"`
+			Snippet        string          `json:"snippet"`
+		} `json:"results"`
+		Total  int64 `json:"total"`
+		Facets map[string][]struct {
+			Value string `json:"value"`
+			Count int64  `json:"count"`
+		} `json:"facets"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+
+	if resp.Total != 2 || len(resp.Results) != 2 {
+		t.Fatalf("expected 2 public matches, got %d: %s", resp.Total, rr.Body.String())
+	}
+	if resp.Results[0].Campaign.ID != titleMatch {
+		t.Errorf("expected the title match to rank first, got %q", resp.Results[0].Campaign.Title)
+	}
+	if resp.Results[0].TitleHighlight != "Clean <mark>water</mark> for Kibera" {
+		t.Errorf("unexpected title highlight %q", resp.Results[0].TitleHighlight)
+	}
+	if snippet := resp.Results[1].Snippet; strings.Contains(snippet, "<script>") || !strings.Contains(snippet, "<mark>water</mark>") {
+		t.Errorf("expected an escaped, highlighted snippet, got %q", snippet)
+	}
+	if len(resp.Facets["category"]) != 2 || len(resp.Facets["status"]) != 1 {
+		t.Errorf("unexpected facets %v", resp.Facets)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/campaigns/search?q=wat&category=education", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Total != 1 || len(resp.Facets["category"]) != 2 {
+		t.Errorf("expected the category filter to narrow results but not facets, got %s", rr.Body.String())
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/campaigns/search?q=%21%21", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an empty query but got %d", http.StatusBadRequest, rr.Code)
+	}
+}
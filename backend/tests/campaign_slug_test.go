@@ -0,0 +1,181 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func campaignSlugTestRouter() *gin.Engine {
+	router := reviewTestRouter()
+	router.POST("/campaigns", controllers.CreateCampaign)
+	router.GET("/campaigns/detail/:id", controllers.GetCampaign)
+	return router
+}
+
+// setupCampaignSlugTestDB also clears the old slugs, which are kept when
+// their campaigns are truncated.
+func setupCampaignSlugTestDB(t *testing.T) *gorm.DB {
+	db := setupCampaignTestDB(t)
+	db.Exec("TRUNCATE TABLE campaignslugredirects")
+	return db
+}
+
+func createSlugTestCampaign(t *testing.T, router *gin.Engine, creator models.User, title string) models.Campaign {
+	rr := sendReviewJSON(router, creator, http.MethodPost, "/campaigns", map[string]interface{}{
+		"title": title, "description": "d", "target_amount": 1000, "currency": "USD", "category": "test",
+		"deadline": time.Now().Add(72 * time.Hour).Format(time.RFC3339),
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Campaign models.Campaign `json:"campaign"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	return resp.Campaign
+}
+
+// TestCreateCampaign_Slugs checks that slugs are transliterated from the
+// title and that repeated titles get a numeric suffix.
+func TestCreateCampaign_Slugs(t *testing.T) {
+	db := setupCampaignSlugTestDB(t)
+	router := campaignSlugTestRouter()
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+
+	for _, tc := range []struct {
+		title string
+		want  string
+	}{
+		{"Café für Kinder!", "cafe-fur-kinder"},
+		{"Cafe fur Kinder", "cafe-fur-kinder-2"},
+		{"Помощь детям", "pomoshch-detyam"},
+		{"!!!", "campaign"},
+		{"¿?", "campaign-2"},
+	} {
+		campaign := createSlugTestCampaign(t, router, creator, tc.title)
+		if campaign.Slug == nil || *campaign.Slug != tc.want {
+			t.Errorf("%q: expected slug %q, got %v", tc.title, tc.want, campaign.Slug)
+		}
+	}
+}
+
+// TestCreateCampaign_ConcurrentSlugs creates campaigns with the same title at
+// once and checks each still gets its own slug.
+func TestCreateCampaign_ConcurrentSlugs(t *testing.T) {
+	db := setupCampaignSlugTestDB(t)
+	router := campaignSlugTestRouter()
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+
+	responses := make([]*httptest.ResponseRecorder, 5)
+	var wg sync.WaitGroup
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = sendReviewJSON(router, creator, http.MethodPost, "/campaigns", map[string]interface{}{
+				"title": "Same Title", "description": "d", "target_amount": 1000, "currency": "USD", "category": "test",
+				"deadline": time.Now().Add(72 * time.Hour).Format(time.RFC3339),
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, rr := range responses {
+		var resp struct {
+			Campaign models.Campaign `json:"campaign"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusCreated || resp.Campaign.Slug == nil || seen[*resp.Campaign.Slug] {
+			t.Fatalf("expected a campaign with its own slug, got status %d. Response: %s", rr.Code, rr.Body.String())
+		}
+		seen[*resp.Campaign.Slug] = true
+	}
+}
+
+// TestGetCampaign_BySlug changes a campaign's slug and checks the new slug and
+// the ID resolve for its team while the old slug redirects and stays reserved.
+func TestGetCampaign_BySlug(t *testing.T) {
+	db := setupCampaignSlugTestDB(t)
+	router := campaignSlugTestRouter()
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	other := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+
+	campaign := createSlugTestCampaign(t, router, creator, "School Roof Repair")
+	rival := createSlugTestCampaign(t, router, other, "Library Books")
+	path := "/campaigns/detail/" + campaign.ID.String()
+
+	rr := sendReviewJSON(router, creator, http.MethodPut, path, map[string]interface{}{"slug": "new-school-roof", "title": "New Title"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	for _, ref := range []string{"new-school-roof", campaign.ID.String()} {
+		rr = sendReviewJSON(router, creator, http.MethodGet, "/campaigns/detail/"+ref, nil)
+		var resp struct {
+			Campaign models.Campaign `json:"campaign"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusOK || resp.Campaign.ID != campaign.ID {
+			t.Errorf("%s: expected the campaign, got status %d. Response: %s", ref, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr = sendReviewJSON(router, creator, http.MethodGet, "/campaigns/detail/school-roof-repair", nil)
+	if rr.Code != http.StatusMovedPermanently || rr.Header().Get("Location") != "/campaigns/detail/new-school-roof" {
+		t.Errorf("expected a redirect to the new slug, got status %d and location %q", rr.Code, rr.Header().Get("Location"))
+	}
+	if rr := sendReviewJSON(router, creator, http.MethodGet, "/campaigns/detail/no-such-campaign", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown slug but got %d", http.StatusNotFound, rr.Code)
+	}
+	// The campaign is still a draft, so only its team can look it up
+	for _, user := range []models.User{{}, other} {
+		if rr := sendReviewJSON(router, user, http.MethodGet, "/campaigns/detail/new-school-roof", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d for a draft outside its team but got %d", http.StatusNotFound, rr.Code)
+		}
+	}
+
+	rivalPath := "/campaigns/detail/" + rival.ID.String()
+	for _, tc := range []struct {
+		slug string
+		want int
+	}{
+		{"school-roof-repair", http.StatusConflict},
+		{"new-school-roof", http.StatusConflict},
+		{"Bad Slug", http.StatusBadRequest},
+		{"ab", http.StatusBadRequest},
+		{uuid.NewString(), http.StatusBadRequest},
+	} {
+		if rr := sendReviewJSON(router, other, http.MethodPut, rivalPath, map[string]interface{}{"slug": tc.slug}); rr.Code != tc.want {
+			t.Errorf("%q: expected status %d but got %d", tc.slug, tc.want, rr.Code)
+		}
+	}
+
+	// A campaign can take back its own old slug
+	rr = sendReviewJSON(router, creator, http.MethodPut, path, map[string]interface{}{"slug": "school-roof-repair"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	rr = sendReviewJSON(router, creator, http.MethodGet, "/campaigns/detail/school-roof-repair", nil)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d for the restored slug but got %d", http.StatusOK, rr.Code)
+	}
+	rr = sendReviewJSON(router, creator, http.MethodGet, "/campaigns/detail/new-school-roof", nil)
+	if rr.Code != http.StatusMovedPermanently {
+		t.Errorf("expected status %d for the replaced slug but got %d", http.StatusMovedPermanently, rr.Code)
+	}
+}
@@ -0,0 +1,187 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupCampaignUpdateTestDB connects to the test PostgreSQL database and
+// migrates the models campaign updates touch.
+func setupCampaignUpdateTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.Donation{}, &models.Notification{},
+		&models.MediaFile{}, &models.CampaignUpdate{}, &models.CampaignMember{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+func campaignUpdateTestRouter() *gin.Engine {
+	router := reviewTestRouter()
+	router.GET("/campaigns/:campaign_id/updates", controllers.ListCampaignUpdates)
+	router.POST("/campaigns/:campaign_id/updates", controllers.CreateCampaignUpdate)
+	router.PUT("/campaigns/:campaign_id/updates/:update_id", controllers.UpdateCampaignUpdate)
+	router.DELETE("/campaigns/:campaign_id/updates/:update_id", controllers.DeleteCampaignUpdate)
+	return router
+}
+
+func createUpdateTestDonation(t *testing.T, db *gorm.DB, campaignID, donorID uuid.UUID) {
+	donation := models.Donation{
+		ID:         uuid.New(),
+		CampaignID: campaignID,
+		DonorID:    donorID,
+		Amount:     25,
+		Currency:   "USD",
+		Status:     "completed",
+	}
+	if err := db.Create(&donation).Error; err != nil {
+		t.Fatalf("failed to create donation: %v", err)
+	}
+}
+
+// TestCampaignUpdates_FanOutAndVisibility posts a donors-only update and
+// checks who is notified and who can read it.
+func TestCampaignUpdates_FanOutAndVisibility(t *testing.T) {
+	db := setupCampaignUpdateTestDB(t)
+	router := campaignUpdateTestRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	donor := createReviewTestUser(t, db, utils.RoleDonor)
+	optedOut := createReviewTestUser(t, db, utils.RoleDonor)
+	db.Model(&optedOut).Update("updates_opt_out", true)
+	stranger := createReviewTestUser(t, db, utils.RoleDonor)
+
+	campaign := models.Campaign{
+		ID: uuid.New(), CreatorID: creator.ID, Title: "Updates campaign", Description: "d",
+		TargetAmount: 1000, Deadline: time.Now().Add(72 * time.Hour), Status: models.CampaignStatusActive,
+		Currency: "USD", Category: "test",
+	}
+	db.Create(&campaign)
+	createUpdateTestDonation(t, db, campaign.ID, donor.ID)
+	createUpdateTestDonation(t, db, campaign.ID, donor.ID)
+	createUpdateTestDonation(t, db, campaign.ID, optedOut.ID)
+
+	path := "/campaigns/" + campaign.ID.String() + "/updates"
+	payload := map[string]interface{}{"title": "Wells dug", "body": "The first two wells are done.", "visibility": "donors"}
+	if rr := sendReviewJSON(router, stranger, http.MethodPost, path, payload); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for a stranger but got %d", http.StatusForbidden, rr.Code)
+	}
+
+	rr := sendReviewJSON(router, creator, http.MethodPost, path, payload)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	var created struct {
+		Notified int `json:"notified"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.Notified != 1 {
+		t.Errorf("expected 1 donor to be notified, got %d", created.Notified)
+	}
+
+	var notifications int64
+	db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", donor.ID, "campaign_update").Count(&notifications)
+	if notifications != 1 {
+		t.Errorf("expected the donor to get 1 notification, got %d", notifications)
+	}
+	db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", optedOut.ID, "campaign_update").Count(&notifications)
+	if notifications != 0 {
+		t.Errorf("expected the opted-out donor to get no notification, got %d", notifications)
+	}
+
+	for _, tc := range []struct {
+		user models.User
+		want int
+	}{{donor, 1}, {creator, 1}, {stranger, 0}, {models.User{}, 0}} {
+		rr := sendReviewJSON(router, tc.user, http.MethodGet, path, nil)
+		var resp struct {
+			Updates []models.CampaignUpdate `json:"updates"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if len(resp.Updates) != tc.want {
+			t.Errorf("user %q: expected %d updates, got %d", tc.user.Role, tc.want, len(resp.Updates))
+		}
+	}
+}
+
+// TestCampaignUpdates_EditAndDelete ensures media from another campaign is
+// refused and that an update can be edited and deleted.
+func TestCampaignUpdates_EditAndDelete(t *testing.T) {
+	db := setupCampaignUpdateTestDB(t)
+	router := campaignUpdateTestRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	campaign := models.Campaign{
+		ID: uuid.New(), CreatorID: creator.ID, Title: "Media campaign", Description: "d",
+		TargetAmount: 1000, Deadline: time.Now().Add(72 * time.Hour), Status: models.CampaignStatusActive,
+		Currency: "USD", Category: "test",
+	}
+	other := campaign
+	other.ID = uuid.New()
+	db.Create(&campaign)
+	db.Create(&other)
+	photo := models.MediaFile{ID: uuid.New(), CampaignID: campaign.ID, FileType: "image", URL: "https://cdn.example/well.jpg", Status: "active"}
+	foreign := models.MediaFile{ID: uuid.New(), CampaignID: other.ID, FileType: "image", URL: "https://cdn.example/other.jpg", Status: "active"}
+	db.Create(&photo)
+	db.Create(&foreign)
+
+	path := "/campaigns/" + campaign.ID.String() + "/updates"
+	rr := sendReviewJSON(router, creator, http.MethodPost, path, map[string]interface{}{
+		"title": "Photos", "body": "Look", "media_ids": []string{foreign.ID.String()},
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for foreign media but got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = sendReviewJSON(router, creator, http.MethodPost, path, map[string]interface{}{
+		"title": "Photos", "body": "Look", "media_ids": []string{photo.ID.String()},
+	})
+	var created struct {
+		Update models.CampaignUpdate `json:"update"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if rr.Code != http.StatusCreated || len(created.Update.Media) != 1 {
+		t.Fatalf("expected an update with 1 media file, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	updatePath := path + "/" + created.Update.ID.String()
+	rr = sendReviewJSON(router, creator, http.MethodPut, updatePath, map[string]interface{}{"title": "More photos", "media_ids": []string{}})
+	var saved struct {
+		Update models.CampaignUpdate `json:"update"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &saved)
+	if rr.Code != http.StatusOK || saved.Update.Title != "More photos" || saved.Update.Body != "Look" || len(saved.Update.Media) != 0 {
+		t.Errorf("unexpected edit result %d %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := sendReviewJSON(router, creator, http.MethodDelete, updatePath, nil); rr.Code != http.StatusOK {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var count int64
+	db.Model(&models.CampaignUpdate{}).Where("id = ?", created.Update.ID).Count(&count)
+	if count != 0 {
+		t.Error("expected the update to be deleted")
+	}
+}
@@ -240,12 +240,12 @@ func TestListCommentsByUserID(t *testing.T) {
 		t.Errorf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
 	}
 
-	var resp []models.Comment
+	var resp map[string][]models.Comment
 	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
-	if len(resp) != len(commentsData) {
-		t.Errorf("expected %d comments, got %d", len(commentsData), len(resp))
+	if len(resp["comments"]) != len(commentsData) {
+		t.Errorf("expected %d comments, got %d", len(commentsData), len(resp["comments"]))
 	}
 }
 
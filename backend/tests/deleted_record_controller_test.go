@@ -0,0 +1,170 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// setupDeletedRecordTestDB migrates every soft-deleted model, as the purge
+// goes through all of them.
+func setupDeletedRecordTestDB(t *testing.T) *gorm.DB {
+	db := setupCampaignTestDB(t)
+	// Recreated by AutoMigrate as a partial index if it predates soft deletes
+	db.Exec("DROP INDEX IF EXISTS idx_campaignmilestones_amount")
+	if err := db.AutoMigrate(&models.CampaignMilestone{}, &models.Comment{}, &models.Donation{},
+		&models.Notification{}, &models.SupportTicket{}, &models.PaymentTransaction{}, &models.Withdrawal{},
+		&models.CampaignUpdate{}, &models.MediaFile{}, &models.RewardTier{}, &models.RewardClaim{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+	db.Exec("TRUNCATE TABLE comments, donations RESTART IDENTITY CASCADE")
+	return db
+}
+
+func deletedRecordTestRouter() *gin.Engine {
+	router := reviewTestRouter()
+	router.GET("/admin/deleted/:resource", controllers.ListDeletedRecords)
+	router.POST("/admin/deleted/:resource/:id/restore", controllers.RestoreDeletedRecord)
+	return router
+}
+
+func createDeletedRecordTestCampaign(t *testing.T, db *gorm.DB, creator models.User) models.Campaign {
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creator.ID,
+		Title:        "Soft Delete Campaign",
+		Description:  "d",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(72 * time.Hour),
+		Currency:     "USD",
+		Category:     "test",
+		Status:       "active",
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	return campaign
+}
+
+// TestRestoreDeletedRecord deletes a comment, finds it in the admin listing
+// and restores it.
+func TestRestoreDeletedRecord(t *testing.T) {
+	db := setupDeletedRecordTestDB(t)
+	router := deletedRecordTestRouter()
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	admin := createReviewTestUser(t, db, utils.RoleAdmin)
+	campaign := createDeletedRecordTestCampaign(t, db, creator)
+
+	comment := models.Comment{CampaignID: campaign.ID, UserID: creator.ID, Content: "Deleted by mistake"}
+	if err := db.Create(&comment).Error; err != nil {
+		t.Fatalf("failed to create comment: %v", err)
+	}
+	if err := db.Delete(&comment).Error; err != nil {
+		t.Fatalf("failed to delete comment: %v", err)
+	}
+	if err := db.First(&models.Comment{}, "id = ?", comment.ID).Error; err == nil {
+		t.Fatal("expected the deleted comment to be hidden")
+	}
+
+	rr := sendReviewJSON(router, admin, http.MethodGet, "/admin/deleted/comments", nil)
+	var resp struct {
+		Records []models.Comment `json:"records"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || len(resp.Records) != 1 || resp.Records[0].ID != comment.ID {
+		t.Fatalf("expected the deleted comment to be listed, got status %d. Response: %s", rr.Code, rr.Body.String())
+	}
+
+	restore := "/admin/deleted/comments/" + comment.ID.String() + "/restore"
+	if rr := sendReviewJSON(router, creator, http.MethodPost, restore, nil); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d without the moderation permission but got %d", http.StatusForbidden, rr.Code)
+	}
+	if rr := sendReviewJSON(router, admin, http.MethodGet, "/admin/deleted/users", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown resource but got %d", http.StatusNotFound, rr.Code)
+	}
+
+	rr = sendReviewJSON(router, admin, http.MethodPost, restore, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if err := db.First(&models.Comment{}, "id = ?", comment.ID).Error; err != nil {
+		t.Errorf("expected the comment to be back: %v", err)
+	}
+	if rr := sendReviewJSON(router, admin, http.MethodPost, restore, nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a comment that is not deleted but got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+// TestRestoreDeletedRecord_MilestoneConflict refuses to restore a milestone
+// whose amount was taken by another one in the meantime.
+func TestRestoreDeletedRecord_MilestoneConflict(t *testing.T) {
+	db := setupDeletedRecordTestDB(t)
+	router := deletedRecordTestRouter()
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	admin := createReviewTestUser(t, db, utils.RoleAdmin)
+	campaign := createDeletedRecordTestCampaign(t, db, creator)
+
+	deleted := models.CampaignMilestone{CampaignID: campaign.ID, Amount: 500, Title: "Halfway"}
+	if err := db.Create(&deleted).Error; err != nil {
+		t.Fatalf("failed to create milestone: %v", err)
+	}
+	db.Delete(&deleted)
+	if err := db.Create(&models.CampaignMilestone{CampaignID: campaign.ID, Amount: 500, Title: "Half"}).Error; err != nil {
+		t.Fatalf("expected the amount of a deleted milestone to be free: %v", err)
+	}
+
+	rr := sendReviewJSON(router, admin, http.MethodPost, "/admin/deleted/campaignmilestones/"+deleted.ID.String()+"/restore", nil)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+}
+
+// TestPurgeDeletedRecords removes rows deleted before the retention period
+// and keeps recent ones and campaigns that received donations.
+func TestPurgeDeletedRecords(t *testing.T) {
+	db := setupDeletedRecordTestDB(t)
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	t.Setenv("SOFT_DELETE_RETENTION_DAYS", "30")
+
+	old := createDeletedRecordTestCampaign(t, db, creator)
+	donated := createDeletedRecordTestCampaign(t, db, creator)
+	recent := createDeletedRecordTestCampaign(t, db, creator)
+	donation := models.Donation{CampaignID: donated.ID, DonorID: creator.ID, Amount: 10, Currency: "USD"}
+	if err := db.Create(&donation).Error; err != nil {
+		t.Fatalf("failed to create donation: %v", err)
+	}
+	for _, campaign := range []models.Campaign{old, donated, recent} {
+		db.Delete(&campaign)
+	}
+	db.Unscoped().Model(&models.Campaign{}).Where("id IN ?", []uuid.UUID{old.ID, donated.ID}).
+		Update("deleted_at", time.Now().Add(-40*24*time.Hour))
+
+	if err := controllers.PurgeDeletedRecords(context.Background()); err != nil {
+		t.Fatalf("purge failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		campaign models.Campaign
+		kept     bool
+	}{
+		{old, false},
+		{donated, true},
+		{recent, true},
+	} {
+		var count int64
+		db.Unscoped().Model(&models.Campaign{}).Where("id = ?", tc.campaign.ID).Count(&count)
+		if (count == 1) != tc.kept {
+			t.Errorf("campaign %s: expected kept to be %v, found %d rows", tc.campaign.ID, tc.kept, count)
+		}
+	}
+}
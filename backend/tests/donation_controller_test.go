@@ -8,6 +8,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -82,7 +83,7 @@ func createTestCampaign(db *gorm.DB, creatorID uuid.UUID, title string) uuid.UUI
 		TargetAmount:  1000,
 		CurrentAmount: 0,
 		Deadline:      time.Now().Add(72 * time.Hour),
-		Status:        "pending",
+		Status:        "active",
 		Currency:      "USD",
 		Category:      "test",
 		CreatedAt:     time.Now(),
@@ -176,7 +177,7 @@ func TestListCampaignDonations(t *testing.T) {
 			Amount:      250.00 + float64(i)*10, // Different amounts if needed
 			Currency:    "USD",
 			Message:     "Keep it up! donation " + strconv.Itoa(i+1),
-			IsAnonymous: false,
+			IsAnonymous: i == 1,
 			Status:      "completed",
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
@@ -197,7 +198,7 @@ func TestListCampaignDonations(t *testing.T) {
 
 	// Decode response into a wrapper struct
 	var resp struct {
-		Donations []models.Donation `json:"donations"`
+		Donations []map[string]interface{} `json:"donations"`
 	}
 	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
@@ -205,6 +206,26 @@ func TestListCampaignDonations(t *testing.T) {
 	if len(resp.Donations) < 2 {
 		t.Errorf("expected at least 2 donations, got %d", len(resp.Donations))
 	}
+
+	// The public list names donors but never exposes their email, and hides
+	// who made an anonymous donation
+	if strings.Contains(rr.Body.String(), "donor2@example.com") {
+		t.Error("expected donor emails to stay private")
+	}
+	for _, donation := range resp.Donations {
+		named := donation["donor_id"] != nil || donation["donor_name"] != nil
+		if anonymous, _ := donation["is_anonymous"].(bool); anonymous == named {
+			t.Errorf("expected the donor to be named only on public donations, got %v", donation)
+		}
+	}
+
+	// Donations of a campaign the public cannot see stay hidden too
+	db.Model(&models.Campaign{}).Where("id = ?", campaignID).Update("status", models.CampaignStatusDraft)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a draft campaign but got %d", http.StatusNotFound, rr.Code)
+	}
 }
 
 // TestListUserDonations tests the ListUserDonations controller.
@@ -0,0 +1,166 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupEmailVerificationTestDB connects to the test PostgreSQL database and migrates the
+// User and RefreshToken models.
+func setupEmailVerificationTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+// createTestUserForVerification creates a user with a unique email. An empty
+// password creates a passwordless guest donor like MakeDonation does.
+func createTestUserForVerification(t *testing.T, db *gorm.DB, role, password string) models.User {
+	uid := uuid.New()
+	var passwordHash string
+	if password != "" {
+		hashed, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		passwordHash = string(hashed)
+	}
+	user := models.User{
+		ID:           uid,
+		Email:        "verify-" + uid.String() + "@example.com",
+		FullName:     "Verify User",
+		Role:         role,
+		Status:       "active",
+		PasswordHash: passwordHash,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	return user
+}
+
+func postVerificationJSON(router *gin.Engine, path string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// TestVerifyEmail tests that a signed verification link marks the email as verified.
+func TestVerifyEmail(t *testing.T) {
+	db := setupEmailVerificationTestDB(t)
+	user := createTestUserForVerification(t, db, "campaign_creator", "secret123")
+
+	token, err := utils.GenerateActionToken(user.ID.String(), user.Email, utils.PurposeEmailVerification, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/email/verify", controllers.VerifyEmail)
+
+	rr := postVerificationJSON(router, "/email/verify", map[string]string{"token": token})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var updated models.User
+	db.First(&updated, "id = ?", user.ID)
+	if !updated.EmailVerified || updated.EmailVerifiedAt == nil {
+		t.Error("expected email to be marked as verified")
+	}
+}
+
+// TestVerifyEmail_WrongPurpose ensures a claim token cannot be used to verify an email.
+func TestVerifyEmail_WrongPurpose(t *testing.T) {
+	db := setupEmailVerificationTestDB(t)
+	user := createTestUserForVerification(t, db, "donor", "")
+
+	token, _ := utils.GenerateActionToken(user.ID.String(), user.Email, utils.PurposeAccountClaim, time.Hour)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/email/verify", controllers.VerifyEmail)
+
+	rr := postVerificationJSON(router, "/email/verify", map[string]string{"token": token})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+}
+
+// TestClaimAccount tests that a guest donor can set a password exactly once.
+func TestClaimAccount(t *testing.T) {
+	db := setupEmailVerificationTestDB(t)
+	user := createTestUserForVerification(t, db, "donor", "")
+
+	token, _ := utils.GenerateActionToken(user.ID.String(), user.Email, utils.PurposeAccountClaim, time.Hour)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/account/claim", controllers.ClaimAccount)
+
+	payload := map[string]string{"token": token, "password": "newpassword123"}
+	rr := postVerificationJSON(router, "/account/claim", payload)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var updated models.User
+	db.First(&updated, "id = ?", user.ID)
+	if err := bcrypt.CompareHashAndPassword([]byte(updated.PasswordHash), []byte("newpassword123")); err != nil {
+		t.Error("expected password to be set")
+	}
+	if !updated.EmailVerified {
+		t.Error("expected claimed account to be verified")
+	}
+
+	// A second claim with the same link must fail.
+	rr = postVerificationJSON(router, "/account/claim", payload)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d but got %d", http.StatusConflict, rr.Code)
+	}
+}
+
+// TestClaimAccount_SessionTokenRejected ensures a session JWT cannot be used as a claim token.
+func TestClaimAccount_SessionTokenRejected(t *testing.T) {
+	db := setupEmailVerificationTestDB(t)
+	user := createTestUserForVerification(t, db, "donor", "")
+
+	token, _ := utils.GenerateToken(user.ID.String(), user.Email, user.Role, user.TokenVersion)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/account/claim", controllers.ClaimAccount)
+
+	rr := postVerificationJSON(router, "/account/claim", map[string]string{"token": token, "password": "newpassword123"})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+}
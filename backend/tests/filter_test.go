@@ -0,0 +1,82 @@
+package controllers_test
+
+import (
+	"strings"
+	"testing"
+
+	"backend/filter"
+	"backend/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var testCampaignFields = filter.Fields{
+	"title":         {Column: "title", Kind: filter.String, Sortable: true},
+	"status":        {Column: "status", Kind: filter.String},
+	"target_amount": {Column: "target_amount", Kind: filter.Number, Sortable: true},
+	"created_at":    {Column: "created_at", Kind: filter.Time, Sortable: true},
+}
+
+// dryRunDB renders SQL without a database connection.
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open dry-run database: %v", err)
+	}
+	return db
+}
+
+// TestFilter_CompilesToParameters ensures values are bound as parameters and
+// never written into the SQL text.
+func TestFilter_CompilesToParameters(t *testing.T) {
+	db := dryRunDB(t)
+	scope, err := testCampaignFields.Filter("status:in:active|paused,target_amount:gte:1000,title:contains:50%'; DROP TABLE users;--")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stmt := db.Model(&models.Campaign{}).Scopes(scope).Find(&[]models.Campaign{}).Statement
+	sql := stmt.SQL.String()
+	if strings.Contains(sql, "DROP") || strings.Contains(sql, "1000") {
+		t.Errorf("expected values to be parameters, got %s", sql)
+	}
+	for _, want := range []string{`"campaigns"."status" IN ($1,$2)`, `"campaigns"."target_amount" >= $3`, `"campaigns"."title" ILIKE $4`} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %s", want, sql)
+		}
+	}
+	if len(stmt.Vars) != 4 || stmt.Vars[3] != `%50\%'; DROP TABLE users;--%` {
+		t.Errorf("unexpected parameters %v", stmt.Vars)
+	}
+}
+
+// TestFilter_Rejects ensures unknown fields, disallowed operators and bad
+// values are refused.
+func TestFilter_Rejects(t *testing.T) {
+	for _, expr := range []string{
+		"password_hash:eq:x",
+		"status:gte:active",
+		"target_amount:contains:10",
+		"target_amount:gt:lots",
+		"created_at:lt:yesterday",
+		"status",
+	} {
+		if _, err := testCampaignFields.Filter(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+// TestSort ensures only sortable fields are accepted and "-" sorts descending.
+func TestSort(t *testing.T) {
+	keys, err := testCampaignFields.Sort("-created_at,title")
+	if err != nil || len(keys) != 2 || !keys[0].Desc || keys[1].Desc || keys[1].Column != "title" {
+		t.Errorf("unexpected keys %+v (%v)", keys, err)
+	}
+	for _, expr := range []string{"status", "id; DROP TABLE users", "title,title", ""} {
+		if _, err := testCampaignFields.Sort(expr); err == nil {
+			t.Errorf("expected sort %q to be rejected", expr)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package controllers_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// generateTestPEM returns a PKCS#8 private key and PKIX public key in PEM form.
+func generateTestPEM(t *testing.T, alg string) (string, string) {
+	var private, public interface{}
+	switch alg {
+	case "RS256":
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate RSA key: %v", err)
+		}
+		private, public = key, &key.PublicKey
+	case "EdDSA":
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate Ed25519 key: %v", err)
+		}
+		private, public = key, pub
+	}
+	privDER, _ := x509.MarshalPKCS8PrivateKey(private)
+	pubDER, _ := x509.MarshalPKIXPublicKey(public)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+}
+
+// useTestKeyRing installs a key ring for the duration of the test.
+func useTestKeyRing(t *testing.T, cfg utils.KeyRingConfig) *utils.KeyRing {
+	ring, err := utils.NewKeyRing(cfg)
+	if err != nil {
+		t.Fatalf("failed to build key ring: %v", err)
+	}
+	previous := utils.JWTKeys
+	utils.JWTKeys = ring
+	t.Cleanup(func() { utils.JWTKeys = previous })
+	return ring
+}
+
+// TestKeyRing_SignsWithKid ensures tokens carry the signing kid and verify.
+func TestKeyRing_SignsWithKid(t *testing.T) {
+	for _, alg := range []string{"RS256", "EdDSA"} {
+		private, _ := generateTestPEM(t, alg)
+		useTestKeyRing(t, utils.KeyRingConfig{
+			SigningKeyID: "current",
+			Keys:         []utils.KeyConfig{{ID: "current", Algorithm: alg, PrivateKey: private}},
+		})
+
+		token, err := utils.GenerateToken("user-1", "user@example.com", "donor", 0)
+		if err != nil {
+			t.Fatalf("%s: failed to generate token: %v", alg, err)
+		}
+		parsed, _, _ := jwt.NewParser().ParseUnverified(token, &utils.Claims{})
+		if parsed.Header["kid"] != "current" || parsed.Header["alg"] != alg {
+			t.Errorf("%s: unexpected header %v", alg, parsed.Header)
+		}
+
+		claims, err := utils.ParseToken(token)
+		if err != nil {
+			t.Fatalf("%s: failed to parse token: %v", alg, err)
+		}
+		if claims.UserID != "user-1" {
+			t.Errorf("%s: expected user-1, got %s", alg, claims.UserID)
+		}
+	}
+}
+
+// TestKeyRing_Rotation ensures tokens from a rotated-out key verify until its not_after.
+func TestKeyRing_Rotation(t *testing.T) {
+	oldPrivate, oldPublic := generateTestPEM(t, "RS256")
+	newPrivate, _ := generateTestPEM(t, "EdDSA")
+
+	useTestKeyRing(t, utils.KeyRingConfig{
+		SigningKeyID: "old",
+		Keys:         []utils.KeyConfig{{ID: "old", Algorithm: "RS256", PrivateKey: oldPrivate}},
+	})
+	oldToken, err := utils.GenerateToken("user-1", "user@example.com", "donor", 0)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	// Rotate: sign with the new key, keep verifying the old one.
+	future := time.Now().Add(time.Hour)
+	useTestKeyRing(t, utils.KeyRingConfig{
+		SigningKeyID: "new",
+		Keys: []utils.KeyConfig{
+			{ID: "new", Algorithm: "EdDSA", PrivateKey: newPrivate},
+			{ID: "old", Algorithm: "RS256", PublicKey: oldPublic, NotAfter: &future},
+		},
+	})
+	if _, err := utils.ParseToken(oldToken); err != nil {
+		t.Errorf("expected old token to verify during the rotation window: %v", err)
+	}
+
+	// Once the window closes the old key is no longer trusted.
+	past := time.Now().Add(-time.Minute)
+	useTestKeyRing(t, utils.KeyRingConfig{
+		SigningKeyID: "new",
+		Keys: []utils.KeyConfig{
+			{ID: "new", Algorithm: "EdDSA", PrivateKey: newPrivate},
+			{ID: "old", Algorithm: "RS256", PublicKey: oldPublic, NotAfter: &past},
+		},
+	})
+	if _, err := utils.ParseToken(oldToken); err == nil {
+		t.Error("expected token from a retired key to be rejected")
+	}
+}
+
+// TestKeyRing_RejectsAlgorithmConfusion ensures an HS256 token cannot use a public key as its secret.
+func TestKeyRing_RejectsAlgorithmConfusion(t *testing.T) {
+	private, public := generateTestPEM(t, "RS256")
+	useTestKeyRing(t, utils.KeyRingConfig{
+		SigningKeyID: "rsa",
+		Keys:         []utils.KeyConfig{{ID: "rsa", Algorithm: "RS256", PrivateKey: private}},
+	})
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, utils.Claims{
+		UserID:           "attacker",
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "impacta", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	forged.Header["kid"] = "rsa"
+	tokenString, _ := forged.SignedString([]byte(public))
+
+	if _, err := utils.ParseToken(tokenString); err == nil {
+		t.Error("expected HS256 token with an RSA kid to be rejected")
+	}
+}
+
+// TestGetJWKS ensures only asymmetric public keys are published.
+func TestGetJWKS(t *testing.T) {
+	rsaPrivate, _ := generateTestPEM(t, "RS256")
+	edPrivate, _ := generateTestPEM(t, "EdDSA")
+	useTestKeyRing(t, utils.KeyRingConfig{
+		SigningKeyID: "ed",
+		Keys: []utils.KeyConfig{
+			{ID: "ed", Algorithm: "EdDSA", PrivateKey: edPrivate},
+			{ID: "rsa", Algorithm: "RS256", PrivateKey: rsaPrivate},
+			{ID: "hmac", Algorithm: "HS256", Secret: "a-shared-secret-value"},
+		},
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
+	req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, rr.Code)
+	}
+
+	var set utils.JWKS
+	if err := json.Unmarshal(rr.Body.Bytes(), &set); err != nil {
+		t.Fatalf("failed to unmarshal JWKS: %v", err)
+	}
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 public keys, got %d", len(set.Keys))
+	}
+	if set.Keys[0].KeyID != "ed" || set.Keys[0].KeyType != "OKP" || set.Keys[0].X == "" {
+		t.Errorf("unexpected Ed25519 JWK: %+v", set.Keys[0])
+	}
+	if set.Keys[1].KeyID != "rsa" || set.Keys[1].KeyType != "RSA" || set.Keys[1].N == "" {
+		t.Errorf("unexpected RSA JWK: %+v", set.Keys[1])
+	}
+}
@@ -0,0 +1,120 @@
+package controllers_test
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupLoginThrottleTestDB connects to the test PostgreSQL database, migrates the User,
+// RefreshToken and LoginThrottle models, and truncates the throttle table.
+func setupLoginThrottleTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.LoginThrottle{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	db.Exec("TRUNCATE TABLE loginthrottles")
+
+	utils.DB = db
+	return db
+}
+
+// createThrottleTestUser creates a user whose password is "secret123".
+func createThrottleTestUser(t *testing.T, db *gorm.DB) models.User {
+	uid := uuid.New()
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.DefaultCost)
+	user := models.User{
+		ID:           uid,
+		Email:        "throttle-" + uid.String() + "@example.com",
+		FullName:     "Throttle User",
+		Role:         utils.RoleDonor,
+		Status:       "active",
+		PasswordHash: string(hashed),
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	return user
+}
+
+// TestLoginUser_LocksOutAfterFailures ensures the account is locked once the limit is reached,
+// even for the correct password, and that an admin can unlock it.
+func TestLoginUser_LocksOutAfterFailures(t *testing.T) {
+	db := setupLoginThrottleTestDB(t)
+	gin.SetMode(gin.TestMode)
+	os.Setenv("LOGIN_MAX_FAILURES", "3")
+	defer os.Unsetenv("LOGIN_MAX_FAILURES")
+	user := createThrottleTestUser(t, db)
+
+	router := gin.Default()
+	router.POST("/login", controllers.LoginUser)
+
+	for i := 0; i < 3; i++ {
+		rr := postSessionJSON(router, "/login", map[string]string{"email": user.Email, "password": "wrong"})
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected status %d, got %d", i+1, http.StatusUnauthorized, rr.Code)
+		}
+	}
+
+	rr := postSessionJSON(router, "/login", map[string]string{"email": user.Email, "password": "secret123"})
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d while locked out, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	if retry, _ := strconv.Atoi(rr.Header().Get("Retry-After")); retry <= 0 {
+		t.Errorf("expected a Retry-After header, got %q", rr.Header().Get("Retry-After"))
+	}
+
+	adminRouter := roleTestRouter(utils.RoleAdmin)
+	adminRouter.POST("/admin/users/:id/unlock", controllers.UnlockUser)
+	rr = sendRoleJSON(adminRouter, http.MethodPost, "/admin/users/"+user.ID.String()+"/unlock", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	rr = postSessionJSON(router, "/login", map[string]string{"email": user.Email, "password": "secret123"})
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d after unlocking, got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
+
+// TestLoginUser_SuccessClearsFailures ensures a successful login forgets earlier failures.
+func TestLoginUser_SuccessClearsFailures(t *testing.T) {
+	db := setupLoginThrottleTestDB(t)
+	gin.SetMode(gin.TestMode)
+	user := createThrottleTestUser(t, db)
+
+	router := gin.Default()
+	router.POST("/login", controllers.LoginUser)
+
+	postSessionJSON(router, "/login", map[string]string{"email": user.Email, "password": "wrong"})
+	rr := postSessionJSON(router, "/login", map[string]string{"email": user.Email, "password": "secret123"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var count int64
+	db.Model(&models.LoginThrottle{}).Where("key = ?", "account:"+user.Email).Count(&count)
+	if count != 0 {
+		t.Errorf("expected account failures to be cleared after a successful login")
+	}
+}
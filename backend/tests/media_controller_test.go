@@ -34,7 +34,7 @@ func setupMediaTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Migrate required models.
-	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.MediaFile{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.MediaFile{}, &models.CampaignMember{}); err != nil {
 		t.Fatalf("failed to migrate models: %v", err)
 	}
 
@@ -224,12 +224,12 @@ func TestListMediaFilesByCampaignID(t *testing.T) {
 		t.Errorf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
 	}
 
-	var mediaFiles []models.MediaFile
-	if err := json.Unmarshal(rr.Body.Bytes(), &mediaFiles); err != nil {
+	var resp map[string][]models.MediaFile
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
-	if len(mediaFiles) != 2 {
-		t.Errorf("expected 2 media files, got %d", len(mediaFiles))
+	if len(resp["media_files"]) != 2 {
+		t.Errorf("expected 2 media files, got %d", len(resp["media_files"]))
 	}
 }
 
@@ -271,12 +271,12 @@ func TestListMediaFilesByUserID(t *testing.T) {
 		t.Errorf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
 	}
 
-	var mediaFiles []models.MediaFile
-	if err := json.Unmarshal(rr.Body.Bytes(), &mediaFiles); err != nil {
+	var resp map[string][]models.MediaFile
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
-	if len(mediaFiles) != 2 {
-		t.Errorf("expected 2 media files, got %d", len(mediaFiles))
+	if len(resp["media_files"]) != 2 {
+		t.Errorf("expected 2 media files, got %d", len(resp["media_files"]))
 	}
 }
 
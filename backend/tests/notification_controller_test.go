@@ -149,7 +149,12 @@ func TestGetNotificationByID(t *testing.T) {
 
 	req, _ := http.NewRequest(http.MethodGet, "/notifications/"+notification.ID.String(), nil)
 	rr := httptest.NewRecorder()
-	router.ServeHTTP(rr, req)
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = req
+	c.Set("claims", createTestClaimsForNotification(testUserID, "campaign_creator"))
+	c.Params = gin.Params{{Key: "id", Value: notification.ID.String()}}
+
+	controllers.GetNotificationByID(c)
 
 	if rr.Code != http.StatusOK {
 		t.Errorf("expected status %d, got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
@@ -294,7 +299,7 @@ func TestUpdateNotificationByID(t *testing.T) {
 		t.Errorf("expected content 'Updated content', got %v", updatedNotif["Content"])
 	}
 
-	// Test update as non-owner should be forbidden.
+	// Test update as non-owner should look like a missing notification.
 	otherUserID := uuid.New().String()
 	otherClaims := createTestClaimsForNotification(otherUserID, "campaign_creator")
 	req, _ = http.NewRequest(http.MethodPut, "/notifications/"+notification.ID.String(), bytes.NewBuffer(jsonPayload))
@@ -307,8 +312,8 @@ func TestUpdateNotificationByID(t *testing.T) {
 
 	controllers.UpdateNotificationByID(c)
 
-	if rr.Code != http.StatusForbidden {
-		t.Errorf("expected status %d for non-owner update, got %d", http.StatusForbidden, rr.Code)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for non-owner update, got %d", http.StatusNotFound, rr.Code)
 	}
 }
 
@@ -376,3 +381,50 @@ func TestBulkDeleteNotifications(t *testing.T) {
 		t.Errorf("expected 0 notifications, got %d", count)
 	}
 }
+
+// TestBulkDeleteNotifications_OtherUser ensures a batch containing someone else's
+// notification is rejected as not found and nothing is deleted.
+func TestBulkDeleteNotifications_OtherUser(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	gin.SetMode(gin.TestMode)
+
+	userID := createTestUserForNotification(t, db, "bulkowner@example.com", "Bulk Owner", "campaign_creator", "dummy")
+	otherID := createTestUserForNotification(t, db, "bulkother@example.com", "Bulk Other", "campaign_creator", "dummy")
+
+	var ids []string
+	for _, owner := range []uuid.UUID{userID, otherID} {
+		notif := models.Notification{
+			ID:        uuid.New(),
+			UserID:    owner,
+			Type:      "campaign_update",
+			Content:   "Policy test",
+			Status:    "unread",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := db.Create(&notif).Error; err != nil {
+			t.Fatalf("failed to create notification: %v", err)
+		}
+		ids = append(ids, notif.ID.String())
+	}
+
+	jsonPayload, _ := json.Marshal(map[string]interface{}{"ids": ids})
+	req, _ := http.NewRequest(http.MethodDelete, "/notifications/bulk", bytes.NewBuffer(jsonPayload))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = req
+	c.Set("claims", createTestClaimsForNotification(userID.String(), "campaign_creator"))
+
+	controllers.BulkDeleteNotifications(c)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d. Response: %s", http.StatusNotFound, rr.Code, rr.Body.String())
+	}
+
+	var count int64
+	db.Model(&models.Notification{}).Where("id IN ?", ids).Count(&count)
+	if count != 2 {
+		t.Errorf("expected both notifications to survive, got %d", count)
+	}
+}
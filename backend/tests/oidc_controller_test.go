@@ -0,0 +1,281 @@
+package controllers_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupOIDCTestDB connects to the test PostgreSQL database and migrates the User,
+// RefreshToken, ExternalIdentity and OIDCLoginState models.
+func setupOIDCTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.ExternalIdentity{}, &models.OIDCLoginState{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+// fakeIdP is a minimal OpenID Connect provider: discovery, JWKS and a token
+// endpoint that checks PKCE. Users "sign in" by calling authorize directly.
+type fakeIdP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu    sync.Mutex
+	codes map[string]fakeGrant
+}
+
+type fakeGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	idp := &fakeIdP{key: key, clientID: "impacta-test", codes: make(map[string]fakeGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "idp-key",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		grant, ok := idp.codes[r.Form.Get("code")]
+		delete(idp.codes, r.Form.Get("code"))
+		idp.mu.Unlock()
+		if !ok || r.Form.Get("client_id") != idp.clientID || utils.PKCEChallenge(r.Form.Get("code_verifier")) != grant.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idp.sign(t, grant.claims)})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *fakeIdP) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "idp-key"
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		t.Fatalf("failed to sign ID token: %v", err)
+	}
+	return signed
+}
+
+// idToken returns standard ID token claims for the subject.
+func (idp *fakeIdP) idToken(subject, email string, verified bool, nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            idp.clientID,
+		"sub":            subject,
+		"email":          email,
+		"email_verified": verified,
+		"nonce":          nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+	}
+}
+
+// authorize plays the user signing in at the provider and returns the code
+// it would redirect back with.
+func (idp *fakeIdP) authorize(t *testing.T, authorizationURL, subject, email string, verified bool) string {
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL: %v", err)
+	}
+	q := parsed.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != idp.clientID {
+		t.Fatalf("authorization URL is missing PKCE or client_id: %s", authorizationURL)
+	}
+	code := uuid.NewString()
+	idp.mu.Lock()
+	idp.codes[code] = fakeGrant{
+		challenge: q.Get("code_challenge"),
+		claims:    idp.idToken(subject, email, verified, q.Get("nonce")),
+	}
+	idp.mu.Unlock()
+	return code
+}
+
+func (idp *fakeIdP) provider(t *testing.T) *utils.OIDCProvider {
+	provider, err := utils.NewOIDCProvider(utils.OIDCProviderConfig{
+		Name:        "testidp",
+		Issuer:      idp.server.URL,
+		ClientID:    idp.clientID,
+		RedirectURL: "https://impacta.example/auth/callback/testidp",
+	})
+	if err != nil {
+		t.Fatalf("failed to configure provider: %v", err)
+	}
+	utils.OIDCProviders = map[string]*utils.OIDCProvider{"testidp": provider}
+	return provider
+}
+
+// oidcSignIn runs the whole flow through the controllers and returns the callback response.
+func oidcSignIn(t *testing.T, router *gin.Engine, idp *fakeIdP, subject, email string, verified bool) (*httptest.ResponseRecorder, string) {
+	req, _ := http.NewRequest(http.MethodGet, "/auth/oidc/testidp/login", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var start struct {
+		AuthorizationURL string `json:"authorization_url"`
+		State            string `json:"state"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &start)
+
+	code := idp.authorize(t, start.AuthorizationURL, subject, email, verified)
+	return postSessionJSON(router, "/auth/oidc/testidp/callback", map[string]string{"code": code, "state": start.State}), start.State
+}
+
+func oidcTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/auth/oidc/:provider/login", controllers.StartOIDCLogin)
+	router.POST("/auth/oidc/:provider/callback", controllers.CompleteOIDCLogin)
+	return router
+}
+
+// TestVerifyIDToken_Rejects ensures tokens for another client or with the wrong nonce are refused.
+func TestVerifyIDToken_Rejects(t *testing.T) {
+	idp := newFakeIdP(t)
+	provider := idp.provider(t)
+	ctx := context.Background()
+
+	valid := idp.sign(t, idp.idToken("sub-1", "a@example.com", true, "nonce-1"))
+	identity, err := provider.VerifyIDToken(ctx, valid, "nonce-1")
+	if err != nil || identity.Subject != "sub-1" || !identity.EmailVerified {
+		t.Fatalf("expected a valid identity, got %+v (%v)", identity, err)
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, valid, "nonce-2"); err == nil {
+		t.Error("expected a nonce mismatch to be rejected")
+	}
+
+	claims := idp.idToken("sub-1", "a@example.com", true, "nonce-1")
+	claims["aud"] = "someone-else"
+	if _, err := provider.VerifyIDToken(ctx, idp.sign(t, claims), "nonce-1"); err == nil {
+		t.Error("expected a token for another client to be rejected")
+	}
+
+	claims = idp.idToken("sub-1", "a@example.com", true, "nonce-1")
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	if _, err := provider.VerifyIDToken(ctx, idp.sign(t, claims), "nonce-1"); err == nil {
+		t.Error("expected an expired token to be rejected")
+	}
+}
+
+// TestOIDCLogin_CreatesThenLinksUser ensures the first sign-in creates an account that
+// later sign-ins reuse, and that a state cannot be redeemed twice.
+func TestOIDCLogin_CreatesThenLinksUser(t *testing.T) {
+	db := setupOIDCTestDB(t)
+	idp := newFakeIdP(t)
+	idp.provider(t)
+	router := oidcTestRouter()
+
+	subject := uuid.NewString()
+	email := "oidc-" + subject + "@example.com"
+
+	rr, state := oidcSignIn(t, router, idp, subject, email, true)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp["new_user"] != true || resp["token"] == nil {
+		t.Errorf("expected a new user with a session, got %v", resp)
+	}
+
+	replay := postSessionJSON(router, "/auth/oidc/testidp/callback", map[string]string{"code": "anything", "state": state})
+	if replay.Code != http.StatusBadRequest {
+		t.Errorf("expected a redeemed state to be rejected with %d, got %d", http.StatusBadRequest, replay.Code)
+	}
+
+	rr, _ = oidcSignIn(t, router, idp, subject, email, true)
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || resp["new_user"] != false {
+		t.Errorf("expected the second sign-in to reuse the account, got %d %v", rr.Code, resp)
+	}
+
+	var user models.User
+	db.First(&user, "email = ?", email)
+	if !user.EmailVerified || user.Role != utils.RoleDonor {
+		t.Errorf("expected a verified donor account, got role %q verified %v", user.Role, user.EmailVerified)
+	}
+	var links int64
+	db.Model(&models.ExternalIdentity{}).Where("user_id = ?", user.ID).Count(&links)
+	if links != 1 {
+		t.Errorf("expected 1 linked identity, got %d", links)
+	}
+}
+
+// TestOIDCLogin_UnverifiedEmail ensures an unverified email cannot claim an account.
+func TestOIDCLogin_UnverifiedEmail(t *testing.T) {
+	setupOIDCTestDB(t)
+	idp := newFakeIdP(t)
+	idp.provider(t)
+	router := oidcTestRouter()
+
+	subject := uuid.NewString()
+	rr, _ := oidcSignIn(t, router, idp, subject, "oidc-"+subject+"@example.com", false)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusForbidden, rr.Code, rr.Body.String())
+	}
+}
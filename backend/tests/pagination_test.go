@@ -0,0 +1,132 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type campaignPage struct {
+	Campaigns  []models.Campaign `json:"campaigns"`
+	NextCursor *string           `json:"next_cursor"`
+	Total      *int64            `json:"total"`
+}
+
+func getCampaignPage(t *testing.T, router *gin.Engine, url string) (int, campaignPage) {
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var page campaignPage
+	json.Unmarshal(rr.Body.Bytes(), &page)
+	return rr.Code, page
+}
+
+func createPagedCampaign(t *testing.T, db *gorm.DB, creatorID uuid.UUID, title string, createdAt time.Time) {
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creatorID,
+		Title:        title,
+		Description:  "Paged campaign",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(72 * time.Hour),
+		Status:       models.CampaignStatusActive,
+		Currency:     "USD",
+		Category:     "test",
+		CreatedAt:    createdAt,
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+}
+
+// TestListCampaigns_CursorPagination walks every page and ensures rows
+// inserted meanwhile neither repeat nor shift the remaining pages.
+func TestListCampaigns_CursorPagination(t *testing.T) {
+	db := setupCampaignTestDB(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/campaigns", controllers.ListCampaigns)
+
+	creatorID := createTestUser1(db, "pages@example.com", "Pager", utils.RoleCampaignCreator, "")
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		createPagedCampaign(t, db, creatorID, "Paged "+string(rune('A'+i)), start.Add(time.Duration(i)*time.Minute))
+	}
+
+	code, page := getCampaignPage(t, router, "/campaigns?limit=2&include_total=true")
+	if code != http.StatusOK || len(page.Campaigns) != 2 || page.NextCursor == nil {
+		t.Fatalf("expected a first page of 2 with a cursor, got %d %+v", code, page)
+	}
+	if page.Total == nil || *page.Total != 5 {
+		t.Errorf("expected total 5, got %v", page.Total)
+	}
+	if page.Campaigns[0].Title != "Paged E" {
+		t.Errorf("expected newest first, got %q", page.Campaigns[0].Title)
+	}
+
+	// A campaign created now sorts before the cursor and must not appear later
+	createPagedCampaign(t, db, creatorID, "Paged late", time.Now())
+
+	seen := map[string]bool{}
+	for _, campaign := range page.Campaigns {
+		seen[campaign.Title] = true
+	}
+	for page.NextCursor != nil {
+		code, page = getCampaignPage(t, router, "/campaigns?limit=2&cursor="+*page.NextCursor)
+		if code != http.StatusOK {
+			t.Fatalf("expected status %d but got %d", http.StatusOK, code)
+		}
+		for _, campaign := range page.Campaigns {
+			if seen[campaign.Title] {
+				t.Errorf("campaign %q returned twice", campaign.Title)
+			}
+			seen[campaign.Title] = true
+		}
+	}
+	if len(seen) != 5 || seen["Paged late"] {
+		t.Errorf("expected exactly the 5 original campaigns, got %v", seen)
+	}
+}
+
+// TestListCampaigns_RejectsBadCursor ensures tampered cursors, cursors for
+// another sort order and oversized limits are refused.
+func TestListCampaigns_RejectsBadCursor(t *testing.T) {
+	db := setupCampaignTestDB(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/campaigns", controllers.ListCampaigns)
+
+	creatorID := createTestUser1(db, "cursor@example.com", "Cursor", utils.RoleCampaignCreator, "")
+	for i := 0; i < 2; i++ {
+		createPagedCampaign(t, db, creatorID, "Cursor campaign", time.Now().Add(-time.Duration(i)*time.Minute))
+	}
+	_, page := getCampaignPage(t, router, "/campaigns?limit=1")
+	if page.NextCursor == nil {
+		t.Fatal("expected a next cursor")
+	}
+	cursor := *page.NextCursor
+
+	payload, signature, _ := strings.Cut(cursor, ".")
+	tampered := payload + "x." + signature
+	for _, url := range []string{
+		"/campaigns?limit=1&cursor=" + tampered,
+		"/campaigns?limit=1&sort_by=title&cursor=" + cursor,
+		"/campaigns?limit=1000",
+		"/campaigns?sort_by=password",
+	} {
+		if code, _ := getCampaignPage(t, router, url); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d but got %d", url, http.StatusBadRequest, code)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupPasswordTestDB connects to the test PostgreSQL database, migrates the User,
+// PasswordResetToken and RefreshToken models, and truncates the reset token table.
+func setupPasswordTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.PasswordResetToken{}, &models.RefreshToken{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	db.Exec("TRUNCATE TABLE passwordresettokens RESTART IDENTITY CASCADE")
+
+	utils.DB = db
+	return db
+}
+
+// createTestUserForPassword creates a user with a unique email and the given password.
+func createTestUserForPassword(t *testing.T, db *gorm.DB, password string) models.User {
+	uid := uuid.New()
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user := models.User{
+		ID:           uid,
+		Email:        "reset-" + uid.String() + "@example.com",
+		FullName:     "Reset User",
+		Role:         "campaign_creator",
+		Status:       "active",
+		PasswordHash: string(hashed),
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	return user
+}
+
+// createTestResetToken stores a reset token for the user and returns the raw token.
+func createTestResetToken(t *testing.T, db *gorm.DB, userID uuid.UUID, expiresAt time.Time) string {
+	raw, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if err := db.Create(&models.PasswordResetToken{UserID: userID, TokenHash: hash, ExpiresAt: expiresAt}).Error; err != nil {
+		t.Fatalf("failed to create reset token: %v", err)
+	}
+	return raw
+}
+
+func postPasswordJSON(router *gin.Engine, path string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// TestForgotPassword_UnknownEmail ensures the endpoint does not reveal whether an email exists.
+func TestForgotPassword_UnknownEmail(t *testing.T) {
+	db := setupPasswordTestDB(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/password/forgot", controllers.ForgotPassword)
+
+	rr := postPasswordJSON(router, "/password/forgot", map[string]string{"email": "nobody-" + uuid.NewString() + "@example.com"})
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var count int64
+	db.Model(&models.PasswordResetToken{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no reset tokens to be created, got %d", count)
+	}
+}
+
+// TestForgotPassword_StoresHashedToken ensures a hashed token is stored for a known user.
+func TestForgotPassword_StoresHashedToken(t *testing.T) {
+	db := setupPasswordTestDB(t)
+	user := createTestUserForPassword(t, db, "oldpassword")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/password/forgot", controllers.ForgotPassword)
+
+	rr := postPasswordJSON(router, "/password/forgot", map[string]string{"email": user.Email})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var tokens []models.PasswordResetToken
+	db.Where("user_id = ?", user.ID).Find(&tokens)
+	if len(tokens) != 1 {
+		t.Fatalf("expected 1 reset token, got %d", len(tokens))
+	}
+	if len(tokens[0].TokenHash) != 64 {
+		t.Errorf("expected a SHA-256 hex hash to be stored, got %q", tokens[0].TokenHash)
+	}
+}
+
+// TestResetPassword_Success ensures a valid token changes the password,
+// bumps the token version and cannot be reused.
+func TestResetPassword_Success(t *testing.T) {
+	db := setupPasswordTestDB(t)
+	user := createTestUserForPassword(t, db, "oldpassword")
+	raw := createTestResetToken(t, db, user.ID, time.Now().Add(time.Hour))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/password/reset", controllers.ResetPassword)
+
+	payload := map[string]string{"token": raw, "password": "newpassword123"}
+	rr := postPasswordJSON(router, "/password/reset", payload)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var updated models.User
+	db.First(&updated, "id = ?", user.ID)
+	if err := bcrypt.CompareHashAndPassword([]byte(updated.PasswordHash), []byte("newpassword123")); err != nil {
+		t.Error("expected password to be updated")
+	}
+	if updated.TokenVersion != user.TokenVersion+1 {
+		t.Errorf("expected token version %d, got %d", user.TokenVersion+1, updated.TokenVersion)
+	}
+
+	// The same token must not work twice.
+	rr = postPasswordJSON(router, "/password/reset", payload)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d on reuse but got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+// TestResetPassword_Expired ensures expired tokens are rejected.
+func TestResetPassword_Expired(t *testing.T) {
+	db := setupPasswordTestDB(t)
+	user := createTestUserForPassword(t, db, "oldpassword")
+	raw := createTestResetToken(t, db, user.ID, time.Now().Add(-time.Minute))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/password/reset", controllers.ResetPassword)
+
+	rr := postPasswordJSON(router, "/password/reset", map[string]string{"token": raw, "password": "newpassword123"})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+}
@@ -158,7 +158,12 @@ func TestGetPaymentTransactionByID(t *testing.T) {
 
 	req, _ := http.NewRequest(http.MethodGet, "/payment_transactions/"+pt.ID.String(), nil)
 	rr := httptest.NewRecorder()
-	router.ServeHTTP(rr, req)
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = req
+	c.Set("claims", createTestClaimsForPayment(uuid.New().String(), "admin"))
+	c.Params = gin.Params{{Key: "id", Value: pt.ID.String()}}
+
+	controllers.GetPaymentTransactionByID(c)
 
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
@@ -209,7 +214,11 @@ func TestListPaymentTransactions(t *testing.T) {
 	url := "/payment_transactions?donation_id=" + donationID.String()
 	req, _ := http.NewRequest(http.MethodGet, url, nil)
 	rr := httptest.NewRecorder()
-	router.ServeHTTP(rr, req)
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = req
+	c.Set("claims", createTestClaimsForPayment(uuid.New().String(), "admin"))
+
+	controllers.ListPaymentTransactions(c)
 
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
@@ -0,0 +1,173 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupPrivacyTestDB connects to the test PostgreSQL database and migrates every model
+// that holds personal data.
+func setupPrivacyTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.Donation{}, &models.Comment{},
+		&models.SupportTicket{}, &models.Notification{}, &models.RefreshToken{}, &models.PasswordResetToken{},
+		&models.APIKey{}, &models.ExternalIdentity{}, &models.LoginThrottle{}, &models.CampaignFollower{},
+		&models.RewardTier{}, &models.RewardClaim{}, &models.CampaignMember{},
+		&models.CampaignInvitation{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+// privacyTestRouter authenticates every request as the given user.
+func privacyTestRouter(user models.User) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("claims", &utils.Claims{UserID: user.ID.String(), Role: user.Role})
+		c.Next()
+	})
+	router.GET("/user/export", controllers.ExportUserData)
+	router.DELETE("/user", controllers.DeleteUser)
+	return router
+}
+
+// createPrivacyTestData creates a donor who donated to and commented on a campaign.
+func createPrivacyTestData(t *testing.T, db *gorm.DB) (models.User, models.Donation, models.Comment) {
+	creator := models.User{ID: uuid.New(), FullName: "Creator", Role: utils.RoleCampaignCreator, Status: models.UserStatusActive}
+	creator.Email = "creator-" + creator.ID.String() + "@example.com"
+	donor := models.User{ID: uuid.New(), FullName: "Jane Donor", PasswordHash: "$2a$10$hash", Role: utils.RoleDonor, Status: models.UserStatusActive}
+	donor.Email = "donor-" + donor.ID.String() + "@example.com"
+	for _, u := range []*models.User{&creator, &donor} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("failed to create test user: %v", err)
+		}
+	}
+
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creator.ID,
+		Title:        "Privacy Campaign",
+		Description:  "Campaign for privacy tests",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(24 * time.Hour),
+		Status:       "active",
+		Currency:     "USD",
+		Category:     "Test",
+	}
+	donation := models.Donation{ID: uuid.New(), CampaignID: campaign.ID, DonorID: donor.ID, Amount: 25, Currency: "USD", Message: "From Jane in Berlin"}
+	comment := models.Comment{ID: uuid.New(), CampaignID: campaign.ID, UserID: donor.ID, Content: "Call me on 555-0100"}
+	notification := models.Notification{ID: uuid.New(), UserID: donor.ID, Type: "new_donation", Content: "Thanks Jane"}
+	for _, record := range []interface{}{&campaign, &donation, &comment, &notification} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("failed to create test record: %v", err)
+		}
+	}
+	return donor, donation, comment
+}
+
+// TestExportUserData ensures the export contains the user's profile and records.
+func TestExportUserData(t *testing.T) {
+	db := setupPrivacyTestDB(t)
+	donor, donation, _ := createPrivacyTestData(t, db)
+
+	req, _ := http.NewRequest(http.MethodGet, "/user/export", nil)
+	rr := httptest.NewRecorder()
+	privacyTestRouter(donor).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if strings.Contains(rr.Body.String(), "$2a$10$hash") {
+		t.Error("expected the password hash to be left out of the export")
+	}
+	if !strings.Contains(rr.Header().Get("Content-Disposition"), "attachment") {
+		t.Error("expected the export to be served as an attachment")
+	}
+
+	var export struct {
+		Profile       models.User           `json:"profile"`
+		Donations     []models.Donation     `json:"donations"`
+		Comments      []models.Comment      `json:"comments"`
+		Notifications []models.Notification `json:"notifications"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &export)
+	if export.Profile.Email != donor.Email {
+		t.Errorf("unexpected profile %+v", export.Profile)
+	}
+	if len(export.Donations) != 1 || export.Donations[0].ID != donation.ID {
+		t.Errorf("expected the donation in the export, got %+v", export.Donations)
+	}
+	if len(export.Comments) != 1 || len(export.Notifications) != 1 {
+		t.Errorf("expected 1 comment and 1 notification, got %d and %d", len(export.Comments), len(export.Notifications))
+	}
+}
+
+// TestDeleteUser_ErasesPersonalData ensures deleting an account anonymizes it but keeps
+// its donations for accounting.
+func TestDeleteUser_ErasesPersonalData(t *testing.T) {
+	db := setupPrivacyTestDB(t)
+	donor, donation, comment := createPrivacyTestData(t, db)
+	router := privacyTestRouter(donor)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/user", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var erased models.User
+	db.First(&erased, "id = ?", donor.ID)
+	if erased.Status != models.UserStatusErased || erased.ErasedAt == nil ||
+		strings.Contains(erased.Email, "donor-") || erased.FullName == donor.FullName {
+		t.Errorf("expected personal data to be erased, got %+v", erased)
+	}
+
+	var kept models.Donation
+	if err := db.First(&kept, "id = ?", donation.ID).Error; err != nil {
+		t.Fatalf("expected the donation to be kept: %v", err)
+	}
+	if kept.Amount != donation.Amount || !kept.IsAnonymous || kept.Message != "" {
+		t.Errorf("expected an anonymous donation for the same amount, got %+v", kept)
+	}
+	var blanked models.Comment
+	db.First(&blanked, "id = ?", comment.ID)
+	if blanked.Content == comment.Content {
+		t.Error("expected the comment content to be removed")
+	}
+	var notifications int64
+	db.Model(&models.Notification{}).Where("user_id = ?", donor.ID).Count(&notifications)
+	if notifications != 0 {
+		t.Errorf("expected notifications to be deleted, got %d", notifications)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d when erasing twice, got %d", http.StatusConflict, rr.Code)
+	}
+}
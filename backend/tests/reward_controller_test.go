@@ -0,0 +1,206 @@
+package controllers_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupRewardTestDB connects to the test PostgreSQL database and migrates
+// the models rewards touch.
+func setupRewardTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.Donation{}, &models.Notification{},
+		&models.CampaignMilestone{}, &models.CampaignFollower{}, &models.RewardTier{}, &models.RewardClaim{},
+		&models.CampaignMember{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+func rewardTestRouter() *gin.Engine {
+	router := reviewTestRouter()
+	router.POST("/donations", controllers.MakeDonation)
+	router.POST("/campaigns/:campaign_id/rewards", controllers.CreateRewardTier)
+	router.GET("/campaigns/:campaign_id/reward-claims", controllers.ListRewardClaims)
+	router.GET("/campaigns/:campaign_id/reward-claims/export", controllers.ExportRewardClaims)
+	router.PUT("/campaigns/:campaign_id/reward-claims/:claim_id", controllers.UpdateRewardClaim)
+	return router
+}
+
+// TestRewards_ClaimAndFulfil claims a limited reward, checks it cannot be
+// oversold, and ships it from the creator's fulfilment list.
+func TestRewards_ClaimAndFulfil(t *testing.T) {
+	db := setupRewardTestDB(t)
+	router := rewardTestRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	stranger := createReviewTestUser(t, db, utils.RoleDonor)
+	campaign := models.Campaign{
+		ID: uuid.New(), CreatorID: creator.ID, Title: "Reward campaign", Description: "d",
+		TargetAmount: 1000, Deadline: time.Now().Add(72 * time.Hour), Status: models.CampaignStatusActive,
+		Currency: "USD", Category: "test",
+	}
+	db.Create(&campaign)
+	base := "/campaigns/" + campaign.ID.String()
+
+	rr := sendReviewJSON(router, creator, http.MethodPost, base+"/rewards", map[string]interface{}{
+		"title": "Signed poster", "minimum_amount": 50, "quantity_limit": 1, "requires_shipping": true,
+	})
+	var created struct {
+		Reward models.RewardTier `json:"reward"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	address := map[string]interface{}{
+		"name": "Ada Donor", "address_line1": "=1 Main Street", "city": "Springfield", "postal_code": "12345",
+		"country": "US", "phone": "555-0100",
+	}
+	donate := func(amount float64, shipping map[string]interface{}) int {
+		rr := sendReviewJSON(router, models.User{}, http.MethodPost, "/donations", map[string]interface{}{
+			"campaign_id": campaign.ID, "donor_name": "Ada Donor", "email": "reward-" + uuid.NewString() + "@example.com",
+			"amount": amount, "currency": "USD", "reward_tier_id": created.Reward.ID, "shipping": shipping,
+		})
+		return rr.Code
+	}
+	if code := donate(20, address); code != http.StatusBadRequest {
+		t.Errorf("expected status %d below the minimum but got %d", http.StatusBadRequest, code)
+	}
+	if code := donate(60, map[string]interface{}{"phone": "555-0100"}); code != http.StatusBadRequest {
+		t.Errorf("expected status %d without an address but got %d", http.StatusBadRequest, code)
+	}
+	if code := donate(60, address); code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d", http.StatusCreated, code)
+	}
+	if code := donate(60, address); code != http.StatusConflict {
+		t.Errorf("expected status %d for a sold-out reward but got %d", http.StatusConflict, code)
+	}
+
+	// The refused donation left nothing behind
+	var donations int64
+	db.Model(&models.Donation{}).Where("campaign_id = ?", campaign.ID).Count(&donations)
+	db.First(&campaign, "id = ?", campaign.ID)
+	if donations != 1 || campaign.CurrentAmount != 60 {
+		t.Errorf("expected 1 donation of 60, got %d totalling %.2f", donations, campaign.CurrentAmount)
+	}
+
+	if rr := sendReviewJSON(router, stranger, http.MethodGet, base+"/reward-claims", nil); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for a stranger but got %d", http.StatusForbidden, rr.Code)
+	}
+	// Claims hold donors' addresses, so editors cannot read them
+	for _, tc := range []struct {
+		role string
+		want int
+	}{{models.CampaignRoleEditor, http.StatusForbidden}, {models.CampaignRoleFinance, http.StatusOK}} {
+		member := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+		db.Create(&models.CampaignMember{CampaignID: campaign.ID, UserID: member.ID, Role: tc.role})
+		for _, path := range []string{base + "/reward-claims", base + "/reward-claims/export"} {
+			if rr := sendReviewJSON(router, member, http.MethodGet, path, nil); rr.Code != tc.want {
+				t.Errorf("%s %s: expected status %d but got %d", tc.role, path, tc.want, rr.Code)
+			}
+		}
+	}
+	rr = sendReviewJSON(router, creator, http.MethodGet, base+"/reward-claims?status=pending", nil)
+	var list struct {
+		Claims []models.RewardClaim `json:"reward_claims"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Claims) != 1 || list.Claims[0].City != "Springfield" || list.Claims[0].RewardTier.Title != "Signed poster" {
+		t.Fatalf("expected 1 pending claim, got %d %s", rr.Code, rr.Body.String())
+	}
+	claim := list.Claims[0]
+
+	if rr := sendReviewJSON(router, creator, http.MethodGet, base+"/reward-claims?reward_tier_id=poster", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a malformed reward_tier_id but got %d", http.StatusBadRequest, rr.Code)
+	}
+	rr = sendReviewJSON(router, creator, http.MethodGet, base+"/reward-claims/export", nil)
+	rows, err := csv.NewReader(strings.NewReader(rr.Body.String())).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("expected a header and 1 row, got %v %q", err, rr.Body.String())
+	}
+	if got := rows[1][9]; got != "'=1 Main Street" {
+		t.Errorf("expected the address to be escaped for spreadsheets, got %q", got)
+	}
+
+	claimPath := base + "/reward-claims/" + claim.ID.String()
+	if rr := sendReviewJSON(router, creator, http.MethodPut, claimPath, map[string]interface{}{"status": "delivered"}); rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d delivering an unshipped reward but got %d", http.StatusConflict, rr.Code)
+	}
+	rr = sendReviewJSON(router, creator, http.MethodPut, claimPath, map[string]interface{}{"status": "shipped", "tracking_number": "1Z999"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var notifications int64
+	db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", claim.DonorID, "reward_shipped").Count(&notifications)
+	if notifications != 1 {
+		t.Errorf("expected the donor to be told their reward shipped, got %d notifications", notifications)
+	}
+}
+
+// TestRewards_CancelReleasesStock cancels a claim and checks the reward can
+// be claimed again.
+func TestRewards_CancelReleasesStock(t *testing.T) {
+	db := setupRewardTestDB(t)
+	router := rewardTestRouter()
+
+	creator := createReviewTestUser(t, db, utils.RoleCampaignCreator)
+	donor := createReviewTestUser(t, db, utils.RoleDonor)
+	campaign := models.Campaign{
+		ID: uuid.New(), CreatorID: creator.ID, Title: "Stock campaign", Description: "d",
+		TargetAmount: 1000, Deadline: time.Now().Add(72 * time.Hour), Status: models.CampaignStatusActive,
+		Currency: "USD", Category: "test",
+	}
+	db.Create(&campaign)
+	limit := 1
+	tier := models.RewardTier{ID: uuid.New(), CampaignID: campaign.ID, Title: "Thank-you call", MinimumAmount: 10, QuantityLimit: &limit, QuantityClaimed: 1}
+	db.Create(&tier)
+	donation := models.Donation{ID: uuid.New(), CampaignID: campaign.ID, DonorID: donor.ID, Amount: 10, Currency: "USD", Status: "completed"}
+	db.Create(&donation)
+	claim := models.RewardClaim{ID: uuid.New(), DonationID: donation.ID, RewardTierID: tier.ID, CampaignID: campaign.ID,
+		DonorID: donor.ID, ContactEmail: donor.Email, Status: models.RewardClaimPending}
+	db.Omit("RewardTier", "Donation").Create(&claim)
+
+	rr := sendReviewJSON(router, creator, http.MethodPut, "/campaigns/"+campaign.ID.String()+"/reward-claims/"+claim.ID.String(),
+		map[string]interface{}{"status": "cancelled"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	db.First(&tier, "id = ?", tier.ID)
+	if tier.QuantityClaimed != 0 {
+		t.Errorf("expected the reward back in stock, got %d claimed", tier.QuantityClaimed)
+	}
+
+	rr = sendReviewJSON(router, models.User{}, http.MethodPost, "/donations", map[string]interface{}{
+		"campaign_id": campaign.ID, "donor_name": "Second", "email": "stock-" + uuid.NewString() + "@example.com",
+		"amount": 10, "currency": "USD", "reward_tier_id": tier.ID,
+	})
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,189 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"backend/controllers"
+	"backend/middlewares"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupRoleTestDB connects to the test PostgreSQL database, migrates the User, Role and
+// RolePermission models, and resets the roles tables to the built-in roles.
+func setupRoleTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Role{}, &models.RolePermission{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	db.Exec("TRUNCATE TABLE rolepermissions, roles RESTART IDENTITY CASCADE")
+	for _, name := range []string{utils.RoleAdmin, utils.RoleCampaignCreator, utils.RoleDonor} {
+		db.Create(&models.Role{Name: name, IsSystem: true})
+		for _, perm := range utils.DefaultRolePermissions[name] {
+			db.Create(&models.RolePermission{RoleName: name, Permission: perm})
+		}
+	}
+
+	utils.DB = db
+	utils.InvalidateRolePermissions()
+	return db
+}
+
+// roleTestRouter builds a router that authenticates every request as the given role.
+func roleTestRouter(role string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set("claims", &utils.Claims{UserID: uuid.NewString(), Role: role})
+		c.Next()
+	})
+	return router
+}
+
+func sendRoleJSON(router *gin.Engine, method, path string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// TestRequire_CustomRole ensures a permission granted to a custom role opens the route.
+func TestRequire_CustomRole(t *testing.T) {
+	setupRoleTestDB(t)
+
+	approve := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "ok"}) }
+
+	router := roleTestRouter("finance")
+	router.PUT("/withdrawals/:id", middlewares.Require(utils.PermWithdrawalApprove), approve)
+
+	rr := sendRoleJSON(router, http.MethodPut, "/withdrawals/1", nil)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d before the role exists, got %d", http.StatusForbidden, rr.Code)
+	}
+
+	adminRouter := roleTestRouter(utils.RoleAdmin)
+	adminRouter.POST("/admin/roles", middlewares.Require(utils.PermRoleManage), controllers.CreateRole)
+	rr = sendRoleJSON(adminRouter, http.MethodPost, "/admin/roles", map[string]interface{}{
+		"name":        "finance",
+		"description": "Approves payouts",
+		"permissions": []string{utils.PermWithdrawalApprove},
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	rr = sendRoleJSON(router, http.MethodPut, "/withdrawals/1", nil)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d after granting the permission, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+// TestRequire_DefaultRoles checks the seeded grants of the built-in roles.
+func TestRequire_DefaultRoles(t *testing.T) {
+	setupRoleTestDB(t)
+
+	cases := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{utils.RoleAdmin, utils.PermWithdrawalApprove, true},
+		{utils.RoleCampaignCreator, utils.PermCampaignCreate, true},
+		{utils.RoleCampaignCreator, utils.PermWithdrawalApprove, false},
+		{utils.RoleDonor, utils.PermWithdrawalCreate, false},
+	}
+	for _, tc := range cases {
+		if got := utils.HasPermission(tc.role, tc.permission); got != tc.want {
+			t.Errorf("HasPermission(%q, %q) = %v, want %v", tc.role, tc.permission, got, tc.want)
+		}
+	}
+}
+
+// TestCreateRole_UnknownPermission ensures roles can only be granted catalogued permissions.
+func TestCreateRole_UnknownPermission(t *testing.T) {
+	setupRoleTestDB(t)
+
+	router := roleTestRouter(utils.RoleAdmin)
+	router.POST("/admin/roles", controllers.CreateRole)
+
+	rr := sendRoleJSON(router, http.MethodPost, "/admin/roles", map[string]interface{}{
+		"name":        "support_agent",
+		"permissions": []string{"everything:all"},
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+}
+
+// TestDeleteRole_SystemRole ensures built-in roles cannot be deleted.
+func TestDeleteRole_SystemRole(t *testing.T) {
+	setupRoleTestDB(t)
+
+	router := roleTestRouter(utils.RoleAdmin)
+	router.DELETE("/admin/roles/:name", controllers.DeleteRole)
+
+	rr := sendRoleJSON(router, http.MethodDelete, "/admin/roles/donor", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+}
+
+// TestAssignUserRole ensures a role change invalidates the user's access tokens.
+func TestAssignUserRole(t *testing.T) {
+	db := setupRoleTestDB(t)
+
+	uid := uuid.New()
+	user := models.User{
+		ID:       uid,
+		Email:    "role-" + uid.String() + "@example.com",
+		FullName: "Role User",
+		Role:     utils.RoleDonor,
+		Status:   "active",
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	router := roleTestRouter(utils.RoleAdmin)
+	router.PUT("/admin/users/:id/role", controllers.AssignUserRole)
+
+	rr := sendRoleJSON(router, http.MethodPut, "/admin/users/"+uid.String()+"/role", map[string]string{"role": "no_such_role"})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown role, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = sendRoleJSON(router, http.MethodPut, "/admin/users/"+uid.String()+"/role", map[string]string{"role": utils.RoleCampaignCreator})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var updated models.User
+	db.First(&updated, "id = ?", uid)
+	if updated.Role != utils.RoleCampaignCreator {
+		t.Errorf("expected role %q, got %q", utils.RoleCampaignCreator, updated.Role)
+	}
+	if updated.TokenVersion != user.TokenVersion+1 {
+		t.Errorf("expected token version to be bumped")
+	}
+}
@@ -0,0 +1,217 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"backend/controllers"
+	"backend/models"
+	"backend/routes"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupSessionTestDB connects to the test PostgreSQL database, migrates the User,
+// RefreshToken and LoginThrottle models, and truncates the token and throttle tables.
+func setupSessionTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.LoginThrottle{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	db.Exec("TRUNCATE TABLE refreshtokens, loginthrottles RESTART IDENTITY CASCADE")
+
+	utils.DB = db
+	return db
+}
+
+// loginTestUserForSession creates a user, logs them in and returns the user and login response.
+func loginTestUserForSession(t *testing.T, db *gorm.DB) (models.User, map[string]interface{}) {
+	uid := uuid.New()
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.DefaultCost)
+	user := models.User{
+		ID:           uid,
+		Email:        "session-" + uid.String() + "@example.com",
+		FullName:     "Session User",
+		Role:         "campaign_creator",
+		Status:       "active",
+		PasswordHash: string(hashed),
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	router := gin.Default()
+	router.POST("/login", controllers.LoginUser)
+	rr := postSessionJSON(router, "/login", map[string]string{"email": user.Email, "password": "secret123"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("login failed with status %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal login response: %v", err)
+	}
+	return user, resp
+}
+
+func postSessionJSON(router *gin.Engine, path string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// TestRefreshToken_Rotates ensures a refresh token is exchanged for a new pair.
+func TestRefreshToken_Rotates(t *testing.T) {
+	db := setupSessionTestDB(t)
+	gin.SetMode(gin.TestMode)
+	_, login := loginTestUserForSession(t, db)
+
+	router := gin.Default()
+	router.POST("/refresh-token", controllers.RefreshToken)
+
+	refreshToken, _ := login["refresh_token"].(string)
+	rr := postSessionJSON(router, "/refresh-token", map[string]string{"refresh_token": refreshToken})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp["refresh_token"] == "" || resp["refresh_token"] == refreshToken {
+		t.Error("expected a new refresh token to be issued")
+	}
+	if _, ok := resp["token"]; !ok {
+		t.Error("expected an access token in response")
+	}
+}
+
+// TestRefreshToken_ReuseRevokesFamily ensures replaying a rotated token revokes its successors.
+func TestRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	db := setupSessionTestDB(t)
+	gin.SetMode(gin.TestMode)
+	user, login := loginTestUserForSession(t, db)
+
+	router := gin.Default()
+	router.POST("/refresh-token", controllers.RefreshToken)
+
+	original, _ := login["refresh_token"].(string)
+	rr := postSessionJSON(router, "/refresh-token", map[string]string{"refresh_token": original})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected first refresh to succeed, got %d", rr.Code)
+	}
+	var rotated map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &rotated)
+
+	// Replaying the original token must fail...
+	rr = postSessionJSON(router, "/refresh-token", map[string]string{"refresh_token": original})
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d on reuse but got %d", http.StatusUnauthorized, rr.Code)
+	}
+
+	// ...and take the legitimately rotated token down with it.
+	rr = postSessionJSON(router, "/refresh-token", map[string]string{"refresh_token": rotated["refresh_token"].(string)})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected rotated token to be revoked, got %d", rr.Code)
+	}
+
+	var live int64
+	db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&live)
+	if live != 0 {
+		t.Errorf("expected no live refresh tokens, got %d", live)
+	}
+}
+
+// TestLogoutAll ensures all refresh tokens are revoked and the token version is bumped.
+func TestLogoutAll(t *testing.T) {
+	db := setupSessionTestDB(t)
+	gin.SetMode(gin.TestMode)
+	user, _ := loginTestUserForSession(t, db)
+	loginTestUserForSession(t, db) // another user's session must survive
+
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logout-all", nil)
+	c.Set("claims", &utils.Claims{UserID: user.ID.String(), Role: user.Role})
+	controllers.LogoutAll(c)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var live int64
+	db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&live)
+	if live != 0 {
+		t.Errorf("expected no live refresh tokens, got %d", live)
+	}
+	db.Model(&models.RefreshToken{}).Where("user_id <> ? AND revoked_at IS NULL", user.ID).Count(&live)
+	if live != 1 {
+		t.Errorf("expected other user's session to survive, got %d live tokens", live)
+	}
+
+	var updated models.User
+	db.First(&updated, "id = ?", user.ID)
+	if updated.TokenVersion != user.TokenVersion+1 {
+		t.Errorf("expected token version to be bumped")
+	}
+}
+
+// TestLogout_Routes signs out through the application's router, so the
+// logout endpoints are checked to be registered behind authentication.
+func TestLogout_Routes(t *testing.T) {
+	db := setupSessionTestDB(t)
+	gin.SetMode(gin.TestMode)
+	_, login := loginTestUserForSession(t, db)
+	router := routes.SetupRouter()
+
+	accessToken, _ := login["token"].(string)
+	refreshToken, _ := login["refresh_token"].(string)
+	send := func(path, token string, payload interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send("/logout-all", "", nil); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d without a token but got %d", http.StatusUnauthorized, rr.Code)
+	}
+	if rr := send("/logout", accessToken, map[string]string{"refresh_token": refreshToken}); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var live int64
+	db.Model(&models.RefreshToken{}).Where("token_hash = ? AND revoked_at IS NULL", utils.HashToken(refreshToken)).Count(&live)
+	if live != 0 {
+		t.Error("expected the refresh token to be revoked")
+	}
+	if rr := send("/logout-all", accessToken, nil); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr := send("/logout-all", accessToken, nil); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected the access token to stop working, got status %d", rr.Code)
+	}
+}
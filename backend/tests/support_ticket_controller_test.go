@@ -163,7 +163,12 @@ func TestGetSupportTicketByID(t *testing.T) {
 
 	req, _ := http.NewRequest(http.MethodGet, "/support_tickets/"+ticket.ID.String(), nil)
 	rr := httptest.NewRecorder()
-	router.ServeHTTP(rr, req)
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = req
+	c.Set("claims", createTestClaimsForSupportTicket(testUserID, "campaign_creator"))
+	c.Params = gin.Params{{Key: "id", Value: ticket.ID.String()}}
+
+	controllers.GetSupportTicketByID(c)
 
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
@@ -313,7 +318,7 @@ func TestUpdateSupportTicketByID(t *testing.T) {
 		t.Errorf("expected status 'resolved', got %v", updatedTicket["Status"])
 	}
 
-	// Test update as non-owner should be forbidden.
+	// Test update as non-owner should look like a missing ticket.
 	otherUserID := uuid.New().String()
 	otherClaims := createTestClaimsForSupportTicket(otherUserID, "campaign_creator")
 	req, _ = http.NewRequest(http.MethodPut, "/support_tickets/"+ticket.ID.String(), bytes.NewBuffer(jsonPayload))
@@ -326,8 +331,8 @@ func TestUpdateSupportTicketByID(t *testing.T) {
 
 	controllers.UpdateSupportTicketByID(c)
 
-	if rr.Code != http.StatusForbidden {
-		t.Errorf("expected status %d for non-owner update, got %d", http.StatusForbidden, rr.Code)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for non-owner update, got %d", http.StatusNotFound, rr.Code)
 	}
 }
 
@@ -0,0 +1,220 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/middlewares"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupTwoFactorTestDB connects to the test PostgreSQL database, migrates the User,
+// RefreshToken and LoginThrottle models, and truncates the token and throttle tables.
+func setupTwoFactorTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.LoginThrottle{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	db.Exec("TRUNCATE TABLE refreshtokens, loginthrottles RESTART IDENTITY CASCADE")
+
+	utils.DB = db
+	return db
+}
+
+// createTwoFactorTestUser creates a user with two-factor authentication enabled.
+func createTwoFactorTestUser(t *testing.T, db *gorm.DB) (models.User, []string) {
+	secret, _ := utils.GenerateTOTPSecret()
+	codes, hashes, _ := utils.GenerateRecoveryCodes()
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.DefaultCost)
+	uid := uuid.New()
+	user := models.User{
+		ID:                uid,
+		Email:             "2fa-" + uid.String() + "@example.com",
+		FullName:          "Two Factor User",
+		Role:              utils.RoleAdmin,
+		Status:            "active",
+		PasswordHash:      string(hashed),
+		TOTPSecret:        secret,
+		TOTPEnabled:       true,
+		TOTPRecoveryCodes: strings.Join(hashes, ","),
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	return user, codes
+}
+
+// TestTOTPCode_RFC6238 checks the code generator against the RFC 6238 SHA-1 test vectors.
+func TestTOTPCode_RFC6238(t *testing.T) {
+	// Base32 of the RFC test key "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tc := range cases {
+		code, err := utils.TOTPCode(secret, utils.TOTPCounter(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode returned error: %v", err)
+		}
+		if code != tc.code {
+			t.Errorf("at %d expected code %s, got %s", tc.unix, tc.code, code)
+		}
+		if _, ok := utils.ValidateTOTP(secret, tc.code, time.Unix(tc.unix+30, 0)); !ok {
+			t.Errorf("expected code %s to be accepted one step later", tc.code)
+		}
+	}
+}
+
+// TestRequireTwoFactor ensures admins need a second factor to reach money-moving routes.
+func TestRequireTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		role      string
+		twoFactor bool
+		want      int
+	}{
+		{utils.RoleAdmin, false, http.StatusForbidden},
+		{utils.RoleAdmin, true, http.StatusOK},
+		{utils.RoleCampaignCreator, false, http.StatusOK},
+	}
+	for _, tc := range cases {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("claims", &utils.Claims{UserID: uuid.NewString(), Role: tc.role, TwoFactor: tc.twoFactor})
+			c.Next()
+		})
+		router.PUT("/withdrawals/:id", middlewares.RequireTwoFactor(), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "ok"})
+		})
+
+		req, _ := http.NewRequest(http.MethodPut, "/withdrawals/1", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != tc.want {
+			t.Errorf("role %q with two_factor=%v: expected status %d, got %d", tc.role, tc.twoFactor, tc.want, rr.Code)
+		}
+	}
+}
+
+// TestLoginUser_TwoFactorChallenge ensures login returns a challenge that a TOTP code redeems once.
+func TestLoginUser_TwoFactorChallenge(t *testing.T) {
+	db := setupTwoFactorTestDB(t)
+	gin.SetMode(gin.TestMode)
+	user, _ := createTwoFactorTestUser(t, db)
+
+	router := gin.Default()
+	router.POST("/login", controllers.LoginUser)
+	router.POST("/login/2fa", controllers.VerifyLoginChallenge)
+
+	rr := postSessionJSON(router, "/login", map[string]string{"email": user.Email, "password": "secret123"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var login map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &login)
+	if _, ok := login["token"]; ok {
+		t.Fatal("expected no access token before the second factor")
+	}
+	challenge, _ := login["challenge_token"].(string)
+
+	code, _ := utils.TOTPCode(user.TOTPSecret, utils.TOTPCounter(time.Now()))
+	rr = postSessionJSON(router, "/login/2fa", map[string]string{"challenge_token": challenge, "code": code})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	token, _ := resp["token"].(string)
+	claims, err := utils.ParseToken(token)
+	if err != nil || !claims.TwoFactor {
+		t.Errorf("expected an access token marked as two-factor")
+	}
+
+	rr = postSessionJSON(router, "/login/2fa", map[string]string{"challenge_token": challenge, "code": code})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected a replayed code to be rejected with %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+// TestVerifyLoginChallenge_RecoveryCode ensures a recovery code works exactly once.
+func TestVerifyLoginChallenge_RecoveryCode(t *testing.T) {
+	db := setupTwoFactorTestDB(t)
+	gin.SetMode(gin.TestMode)
+	user, codes := createTwoFactorTestUser(t, db)
+
+	router := gin.Default()
+	router.POST("/login/2fa", controllers.VerifyLoginChallenge)
+
+	challenge, _ := utils.GenerateActionToken(user.ID.String(), user.Email, utils.PurposeLoginChallenge, time.Minute)
+	rr := postSessionJSON(router, "/login/2fa", map[string]string{"challenge_token": challenge, "code": strings.ToUpper(codes[0])})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if remaining, _ := resp["recovery_codes_remaining"].(float64); int(remaining) != len(codes)-1 {
+		t.Errorf("expected %d recovery codes remaining, got %v", len(codes)-1, resp["recovery_codes_remaining"])
+	}
+
+	rr = postSessionJSON(router, "/login/2fa", map[string]string{"challenge_token": challenge, "code": codes[0]})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected a used recovery code to be rejected with %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+// TestLoginUser_PasswordKeepsCodeFailures ensures that logging in again with
+// the password does not forget wrong two-factor codes, so they stay limited.
+func TestLoginUser_PasswordKeepsCodeFailures(t *testing.T) {
+	db := setupTwoFactorTestDB(t)
+	gin.SetMode(gin.TestMode)
+	user, _ := createTwoFactorTestUser(t, db)
+
+	router := gin.Default()
+	router.POST("/login", controllers.LoginUser)
+	router.POST("/login/2fa", controllers.VerifyLoginChallenge)
+
+	challenge, _ := utils.GenerateActionToken(user.ID.String(), user.Email, utils.PurposeLoginChallenge, time.Minute)
+	rr := postSessionJSON(router, "/login/2fa", map[string]string{"challenge_token": challenge, "code": "000000"})
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d for a wrong code, got %d", http.StatusUnauthorized, rr.Code)
+	}
+	rr = postSessionJSON(router, "/login", map[string]string{"email": user.Email, "password": "secret123"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var count int64
+	db.Model(&models.LoginThrottle{}).Where("key = ?", "account:"+user.Email).Count(&count)
+	if count != 1 {
+		t.Errorf("expected the wrong code to still count against the account")
+	}
+}
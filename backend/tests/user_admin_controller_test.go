@@ -0,0 +1,175 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/middlewares"
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupUserAdminTestDB connects to the test PostgreSQL database and migrates the User,
+// RefreshToken and Campaign models.
+func setupUserAdminTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DATABASE_URL environment variable is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.Campaign{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
+	utils.DB = db
+	return db
+}
+
+// createUserAdminTestUser creates an active user with the given role.
+func createUserAdminTestUser(t *testing.T, db *gorm.DB, role string) models.User {
+	uid := uuid.New()
+	user := models.User{
+		ID:       uid,
+		Email:    "status-" + uid.String() + "@example.com",
+		FullName: "Status User",
+		Role:     role,
+		Status:   models.UserStatusActive,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	return user
+}
+
+// TestBanUser_PausesCampaigns ensures a ban records the reason, pauses the user's live
+// campaigns and makes their existing tokens unusable.
+func TestBanUser_PausesCampaigns(t *testing.T) {
+	db := setupUserAdminTestDB(t)
+	creator := createUserAdminTestUser(t, db, utils.RoleCampaignCreator)
+
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creator.ID,
+		Title:        "Ban Campaign",
+		Description:  "Campaign of a banned user",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(24 * time.Hour),
+		Status:       "active",
+		Currency:     "USD",
+		Category:     "Test",
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create test campaign: %v", err)
+	}
+	pending := campaign
+	pending.ID = uuid.New()
+	pending.Status = models.CampaignStatusPendingReview
+	if err := db.Create(&pending).Error; err != nil {
+		t.Fatalf("failed to create test campaign: %v", err)
+	}
+	token, _ := utils.GenerateToken(creator.ID.String(), creator.Email, creator.Role, creator.TokenVersion)
+
+	router := roleTestRouter(utils.RoleAdmin)
+	router.POST("/admin/users/:id/ban", controllers.BanUser)
+
+	rr := sendRoleJSON(router, http.MethodPost, "/admin/users/"+creator.ID.String()+"/ban", map[string]interface{}{})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d without a reason, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = sendRoleJSON(router, http.MethodPost, "/admin/users/"+creator.ID.String()+"/ban", map[string]interface{}{
+		"reason":          "Fraudulent campaign",
+		"pause_campaigns": true,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var banned models.User
+	db.First(&banned, "id = ?", creator.ID)
+	if banned.Status != models.UserStatusBanned || banned.StatusReason != "Fraudulent campaign" {
+		t.Errorf("expected banned status with reason, got %q (%q)", banned.Status, banned.StatusReason)
+	}
+	var paused models.Campaign
+	db.First(&paused, "id = ?", campaign.ID)
+	if paused.Status != models.CampaignStatusPaused {
+		t.Errorf("expected campaign to be paused, got %q", paused.Status)
+	}
+	db.First(&pending, "id = ?", pending.ID)
+	if pending.Status != models.CampaignStatusPendingReview {
+		t.Errorf("expected the campaign pending review to be left alone, got %q", pending.Status)
+	}
+
+	gin.SetMode(gin.TestMode)
+	protected := gin.New()
+	protected.GET("/user", middlewares.JWTAuthMiddleware(), controllers.GetUser)
+	req, _ := http.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	if rec.Code == http.StatusOK {
+		t.Errorf("expected the banned user's token to be rejected")
+	}
+}
+
+// TestSuspendUser_Reactivate ensures a suspended user can be reactivated but not suspended twice.
+func TestSuspendUser_Reactivate(t *testing.T) {
+	db := setupUserAdminTestDB(t)
+	donor := createUserAdminTestUser(t, db, utils.RoleDonor)
+
+	router := roleTestRouter(utils.RoleAdmin)
+	router.POST("/admin/users/:id/suspend", controllers.SuspendUser)
+	router.POST("/admin/users/:id/reactivate", controllers.ReactivateUser)
+
+	path := "/admin/users/" + donor.ID.String()
+	rr := sendRoleJSON(router, http.MethodPost, path+"/suspend", map[string]string{"reason": "Chargeback investigation"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	rr = sendRoleJSON(router, http.MethodPost, path+"/suspend", map[string]string{"reason": "Again"})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d for a repeated suspension, got %d", http.StatusConflict, rr.Code)
+	}
+	rr = sendRoleJSON(router, http.MethodPost, path+"/reactivate", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var reactivated models.User
+	db.First(&reactivated, "id = ?", donor.ID)
+	if reactivated.Status != models.UserStatusActive {
+		t.Errorf("expected status %q, got %q", models.UserStatusActive, reactivated.Status)
+	}
+}
+
+// TestUpdateUser_CannotChangeOwnRole ensures users can no longer promote themselves.
+func TestUpdateUser_CannotChangeOwnRole(t *testing.T) {
+	db := setupUserAdminTestDB(t)
+	donor := createUserAdminTestUser(t, db, utils.RoleDonor)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("claims", &utils.Claims{UserID: donor.ID.String(), Role: donor.Role})
+		c.Next()
+	})
+	router.PUT("/user", controllers.UpdateUser)
+
+	rr := sendRoleJSON(router, http.MethodPut, "/user", map[string]string{"role": utils.RoleCampaignCreator})
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusForbidden, rr.Code, rr.Body.String())
+	}
+}
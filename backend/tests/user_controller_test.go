@@ -34,7 +34,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 
 	// Migrate the models required by your controllers.
 	// This will create the tables in the test database.
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.LoginThrottle{}); err != nil {
 		t.Fatalf("failed to migrate User model: %v", err)
 	}
 	// Migrate additional models if needed:
@@ -45,6 +45,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 
 	// Optional: clean up the table before tests run.
 	db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
+	db.Exec("TRUNCATE TABLE loginthrottles")
 	return db
 }
 
@@ -119,18 +120,18 @@ func TestRegisterUser_ExistingUser(t *testing.T) {
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	// Expect HTTP 200 OK because the user is already registered.
-	if rr.Code != http.StatusOK {
-		t.Errorf("expected status %d but got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
+	// Expect HTTP 409 Conflict because the user is already registered.
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d but got %d. Response: %s", http.StatusConflict, rr.Code, rr.Body.String())
 	}
 
-	// Decode response and check that a token is returned.
+	// Decode response and check that no token is handed out.
 	var resp map[string]interface{}
 	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
-	if _, ok := resp["token"]; !ok {
-		t.Error("expected token in response, but none found")
+	if _, ok := resp["token"]; ok {
+		t.Error("expected no token in response for an existing account")
 	}
 }
 
@@ -226,6 +227,10 @@ func TestGetUser(t *testing.T) {
 func TestMain(m *testing.M) {
 	// Optional: Setup global test configurations.
 	log.Println("Running tests...")
+	// Tokens need a signing key; use a fixed one unless keys are configured
+	if os.Getenv("JWT_KEYS") == "" && os.Getenv("JWT_KEYS_FILE") == "" && os.Getenv("JWT_SECRET") == "" {
+		os.Setenv("JWT_SECRET", "test_jwt_secret")
+	}
 	code := m.Run()
 	os.Exit(code)
 }
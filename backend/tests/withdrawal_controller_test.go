@@ -86,18 +86,36 @@ func TestCreateWithdrawal(t *testing.T) {
 	router := gin.Default()
 	router.POST("/withdrawals", controllers.CreateWithdrawal)
 
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.CampaignMember{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+
 	// Create a test user to simulate an authenticated request.
 	userID := createTestUserForWithdrawal(t, db, "withdrawalcreator@example.com", "Withdrawal Creator", "campaign_creator", "dummy")
 	claims := createTestClaimsForWithdrawal(userID.String(), "campaign_creator")
 
-	// Use a dummy campaign ID.
-	campaignID := uuid.New()
+	// Withdrawals can only be requested for the user's own campaign.
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    userID,
+		Title:        "Withdrawal Campaign",
+		Description:  "Campaign used for withdrawal tests",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(24 * time.Hour),
+		Status:       "active",
+		Currency:     "USD",
+		Category:     "Health",
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	campaignID := campaign.ID
 
 	// Prepare the request payload.
 	payload := map[string]interface{}{
 		"campaign_id": campaignID.String(),
 		"amount":      150.0,
-		"status":      "pending",
+		"status":      "processed", // ignored: new withdrawals always wait for approval
 	}
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
@@ -136,6 +154,22 @@ func TestCreateWithdrawal(t *testing.T) {
 	if err := db.Where("amount = ?", 150.0).First(&withdrawal).Error; err != nil {
 		t.Errorf("failed to find withdrawal in DB: %v", err)
 	}
+	if withdrawal.Status != "pending" {
+		t.Errorf("expected a pending withdrawal, got %q", withdrawal.Status)
+	}
+
+	for _, amount := range []float64{0, -50} {
+		body, _ := json.Marshal(map[string]interface{}{"campaign_id": campaignID.String(), "amount": amount})
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/withdrawals", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("claims", claims)
+		controllers.CreateWithdrawal(c)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("amount %v: expected status %d, got %d", amount, http.StatusBadRequest, rr.Code)
+		}
+	}
 }
 
 // TestGetWithdrawalByID tests retrieving a withdrawal by its ID.
@@ -160,7 +194,12 @@ func TestGetWithdrawalByID(t *testing.T) {
 
 	req, _ := http.NewRequest(http.MethodGet, "/withdrawals/"+withdrawal.ID.String(), nil)
 	rr := httptest.NewRecorder()
-	router.ServeHTTP(rr, req)
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = req
+	c.Set("claims", createTestClaimsForWithdrawal(uuid.New().String(), "admin"))
+	c.Params = gin.Params{{Key: "id", Value: withdrawal.ID.String()}}
+
+	controllers.GetWithdrawalByID(c)
 
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
@@ -205,7 +244,11 @@ func TestListWithdrawals(t *testing.T) {
 	url := "/withdrawals?campaign_id=" + campaignID.String()
 	req, _ := http.NewRequest(http.MethodGet, url, nil)
 	rr := httptest.NewRecorder()
-	router.ServeHTTP(rr, req)
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = req
+	c.Set("claims", createTestClaimsForWithdrawal(uuid.New().String(), "admin"))
+
+	controllers.ListWithdrawals(c)
 
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d. Response: %s", http.StatusOK, rr.Code, rr.Body.String())
@@ -346,3 +389,51 @@ func TestBulkDeleteWithdrawals(t *testing.T) {
 		t.Errorf("expected 0 withdrawals, got %d", count)
 	}
 }
+
+// TestGetWithdrawalByID_NotCampaignCreator ensures only the campaign creator can see a
+// withdrawal and that everyone else gets a 404 rather than a 403.
+func TestGetWithdrawalByID_NotCampaignCreator(t *testing.T) {
+	db := setupWithdrawalTestDB(t)
+	if err := db.AutoMigrate(&models.User{}, &models.Campaign{}, &models.CampaignMember{}); err != nil {
+		t.Fatalf("failed to migrate models: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+
+	creatorID := createTestUserForWithdrawal(t, db, "owner@example.com", "Owner", "campaign_creator", "dummy")
+	otherID := createTestUserForWithdrawal(t, db, "other@example.com", "Other", "campaign_creator", "dummy")
+	campaign := models.Campaign{
+		ID:           uuid.New(),
+		CreatorID:    creatorID,
+		Title:        "Policy Campaign",
+		Description:  "Campaign used for withdrawal policy tests",
+		TargetAmount: 1000,
+		Deadline:     time.Now().Add(24 * time.Hour),
+		Status:       "active",
+		Currency:     "USD",
+		Category:     "Health",
+	}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	withdrawal := models.Withdrawal{ID: uuid.New(), CampaignID: campaign.ID, Amount: 75.0, Status: "pending"}
+	if err := db.Create(&withdrawal).Error; err != nil {
+		t.Fatalf("failed to create withdrawal: %v", err)
+	}
+
+	get := func(userID uuid.UUID) int {
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/withdrawals/"+withdrawal.ID.String(), nil)
+		c.Set("claims", createTestClaimsForWithdrawal(userID.String(), "campaign_creator"))
+		c.Params = gin.Params{{Key: "id", Value: withdrawal.ID.String()}}
+		controllers.GetWithdrawalByID(c)
+		return rr.Code
+	}
+
+	if code := get(creatorID); code != http.StatusOK {
+		t.Errorf("expected campaign creator to get status %d, got %d", http.StatusOK, code)
+	}
+	if code := get(otherID); code != http.StatusNotFound {
+		t.Errorf("expected other creator to get status %d, got %d", http.StatusNotFound, code)
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+)
+
+// API key scopes. A key can only reach routes that accept its scope, and
+// never more than its owner's role allows.
+const (
+	ScopeDonationsRead   = "donations:read"
+	ScopePaymentsRead    = "payments:read"
+	ScopeWithdrawalsRead = "withdrawals:read"
+)
+
+// APIKeyScopes lists every scope that can be granted to an API key.
+var APIKeyScopes = []Permission{
+	{ScopeDonationsRead, "List donations (with the donation:read permission)"},
+	{ScopePaymentsRead, "Read payment transactions"},
+	{ScopeWithdrawalsRead, "Read withdrawals"},
+}
+
+// IsAPIKeyScope reports whether name is a known API key scope.
+func IsAPIKeyScope(name string) bool {
+	for _, s := range APIKeyScopes {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyMarker starts every API key so that keys are easy to recognise, e.g.
+// by secret scanners, and cannot be mistaken for a JWT.
+const apiKeyMarker = "imp_"
+
+var apiKeyPrefixEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateAPIKey returns a new key of the form "imp_<prefix>_<secret>", its
+// public prefix and the hash to store. The raw key is shown to the user once.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 5)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	prefix = strings.ToLower(apiKeyPrefixEncoding.EncodeToString(buf))
+	secret, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyMarker + prefix + "_" + secret
+	return key, prefix, HashToken(key), nil
+}
+
+// IsAPIKey reports whether a credential looks like an API key rather than a JWT.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyMarker)
+}
+
+// APIKeyPrefix extracts the public prefix used to look a key up.
+func APIKeyPrefix(key string) (string, bool) {
+	rest := strings.TrimPrefix(key, apiKeyMarker)
+	prefix, secret, found := strings.Cut(rest, "_")
+	if !IsAPIKey(key) || !found || len(prefix) != 8 || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
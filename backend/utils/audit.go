@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// AuditChangesKey is the context key under which handlers leave the changes
+// they made for the audit log middleware.
+const AuditChangesKey = "audit_changes"
+
+// AuditChange describes the change a request made to one record. Before is
+// nil for a record that was created and After is nil for one that was
+// deleted.
+type AuditChange struct {
+	ResourceType string
+	ResourceID   string
+	Before       interface{}
+	After        interface{}
+}
+
+// AuditDiff renders the before and after states of a record as JSON. When
+// both are given only the fields that differ are kept.
+func AuditDiff(before, after interface{}) (string, string, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return "", "", err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return "", "", err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for field, value := range beforeFields {
+			if other, ok := afterFields[field]; ok && reflect.DeepEqual(value, other) {
+				delete(beforeFields, field)
+				delete(afterFields, field)
+			}
+		}
+	}
+	return auditJSON(beforeFields), auditJSON(afterFields), nil
+}
+
+// auditFields converts a record to a map of its JSON fields.
+func auditFields(record interface{}) (map[string]interface{}, error) {
+	if record == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func auditJSON(fields map[string]interface{}) string {
+	if fields == nil {
+		return ""
+	}
+	// Marshalling a map sorts the keys, so the text is stable for hashing
+	data, _ := json.Marshal(fields)
+	return string(data)
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetEnv returns the value of the environment variable named by key,
+// or fallback when it is unset or empty.
+func GetEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// FrontendURL builds an absolute link into the web app, e.g. for emails.
+func FrontendURL(path string) string {
+	base := strings.TrimRight(GetEnv("FRONTEND_URL", "https://yourapp.com"), "/")
+	return base + path
+}
+
+// GetEnvInt returns the integer value of the environment variable named by
+// key, or fallback when it is unset or not a number.
+func GetEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
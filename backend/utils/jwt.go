@@ -1,48 +1,125 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("your_jwt_secret") 
+// jwtIssuer is the "iss" claim other services should expect on Impacta tokens.
+func jwtIssuer() string {
+	return GetEnv("JWT_ISSUER", "impacta")
+}
+
+// AccessTokenTTL is deliberately short; clients renew access tokens with a
+// refresh token instead of keeping one alive for a day.
+const AccessTokenTTL = 15 * time.Minute
 
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"` // Add Role to claims
+	// TokenVersion must match users.token_version; bumping the column
+	// (e.g. after a password reset) invalidates every outstanding token.
+	TokenVersion int `json:"tv"`
+	// TwoFactor is set when the session was established with a TOTP or
+	// recovery code, and carried over when the session is refreshed.
+	TwoFactor bool `json:"mfa,omitempty"`
 	jwt.RegisteredClaims
 }
 
 
 // GenerateToken generates a JWT token for the user
-// GenerateToken generates a JWT token for the user
-func GenerateToken(userID, email, role string) (string, error) {
+func GenerateToken(userID, email, role string, tokenVersion int) (string, error) {
+	return generateSessionToken(userID, email, role, tokenVersion, false)
+}
+
+// GenerateTwoFactorToken generates a JWT token for a user who has completed a second factor.
+func GenerateTwoFactorToken(userID, email, role string, tokenVersion int) (string, error) {
+	return generateSessionToken(userID, email, role, tokenVersion, true)
+}
+
+func generateSessionToken(userID, email, role string, tokenVersion int, twoFactor bool) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role, // Add role to claims
+		UserID:       userID,
+		Email:        email,
+		Role:         role, // Add role to claims
+		TokenVersion: tokenVersion,
+		TwoFactor:    twoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token expires in 24 hours
+			Issuer:    jwtIssuer(),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return keys().Sign(claims)
 }
 
 
 // ParseToken verifies the JWT token and extracts claims
 func ParseToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys().Keyfunc, jwt.WithIssuer(jwtIssuer()))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	// Action tokens carry an audience and must never be accepted as sessions
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("not a session token")
+	}
+	return claims, nil
+}
+
+// Purposes for action tokens, e.g. signed links sent by email.
+const (
+	PurposeEmailVerification = "email_verification"
+	PurposeAccountClaim      = "account_claim"
+	PurposeLoginChallenge    = "login_challenge" // Password accepted, second factor pending
+	PurposeUpdatesOptOut     = "updates_opt_out" // Unsubscribe link in campaign update emails
+)
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
-	} else {
+// ActionClaims are carried by signed links that authorize one specific
+// action (verifying an email, claiming a guest account) rather than a session.
+type ActionClaims struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// GenerateActionToken signs a token that is only valid for the given purpose.
+func GenerateActionToken(userID, email, purpose string, ttl time.Duration) (string, error) {
+	claims := ActionClaims{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    jwtIssuer(),
+			Audience:  jwt.ClaimStrings{purpose},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return keys().Sign(claims)
+}
+
+// ParseActionToken verifies a token produced by GenerateActionToken for the given purpose.
+func ParseActionToken(tokenString, purpose string) (*ActionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, keys().Keyfunc,
+		jwt.WithIssuer(jwtIssuer()), jwt.WithAudience(purpose))
+	if err != nil {
 		return nil, err
 	}
+
+	claims, ok := token.Claims.(*ActionClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
 }
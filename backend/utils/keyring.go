@@ -0,0 +1,315 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyConfig describes one JWT key. HS256 keys use Secret; RS256 and EdDSA keys
+// use a PEM encoded private key (to sign and verify) or only a public key
+// (to keep verifying tokens from a key that has been rotated out).
+type KeyConfig struct {
+	ID             string     `json:"kid"`
+	Algorithm      string     `json:"alg"` // HS256, RS256 or EdDSA
+	Secret         string     `json:"secret,omitempty"`
+	PrivateKey     string     `json:"private_key,omitempty"`
+	PrivateKeyFile string     `json:"private_key_file,omitempty"`
+	PublicKey      string     `json:"public_key,omitempty"`
+	PublicKeyFile  string     `json:"public_key_file,omitempty"`
+	NotAfter       *time.Time `json:"not_after,omitempty"` // Stop accepting tokens signed with this key after this time
+}
+
+// KeyRingConfig is the JSON document read from JWT_KEYS or JWT_KEYS_FILE, e.g.
+//
+//	{
+//	  "signing_kid": "2025-06",
+//	  "keys": [
+//	    {"kid": "2025-06", "alg": "EdDSA", "private_key_file": "/etc/impacta/jwt-2025-06.pem"},
+//	    {"kid": "2025-01", "alg": "RS256", "public_key_file": "/etc/impacta/jwt-2025-01.pub", "not_after": "2025-07-01T00:00:00Z"}
+//	  ]
+//	}
+type KeyRingConfig struct {
+	SigningKeyID string      `json:"signing_kid"`
+	Keys         []KeyConfig `json:"keys"`
+}
+
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{} // nil for verify-only keys
+	verifyKey interface{}
+	notAfter  *time.Time
+}
+
+// KeyRing signs tokens with one active key and verifies them against every
+// key that has not passed its NotAfter, so keys can be rotated without
+// logging everybody out.
+type KeyRing struct {
+	signing *jwtKey
+	keys    map[string]*jwtKey
+}
+
+// JWKS is the JSON Web Key Set published for other services.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a single public key in a JWKS. Symmetric keys are never published.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWTKeys is the key ring used to sign and verify every token.
+var JWTKeys *KeyRing
+
+var keyRingOnce sync.Once
+
+// InitKeyRing loads JWTKeys from configuration. Without JWT_KEYS or
+// JWT_KEYS_FILE it falls back to a single HS256 key built from JWT_SECRET,
+// and it exits when none of them is set.
+func InitKeyRing() {
+	keyRingOnce.Do(func() {
+		if JWTKeys != nil {
+			return
+		}
+		cfg, err := loadKeyRingConfig()
+		if err != nil {
+			log.Fatal("Failed to load JWT keys:", err)
+		}
+		JWTKeys, err = NewKeyRing(cfg)
+		if err != nil {
+			log.Fatal("Failed to load JWT keys:", err)
+		}
+	})
+}
+
+func keys() *KeyRing {
+	InitKeyRing()
+	return JWTKeys
+}
+
+// PublicJWKS returns the key set of the key ring, loading it first if needed.
+func PublicJWKS() JWKS {
+	return keys().JWKS()
+}
+
+func loadKeyRingConfig() (KeyRingConfig, error) {
+	var cfg KeyRingConfig
+	data := []byte(os.Getenv("JWT_KEYS"))
+	if path := os.Getenv("JWT_KEYS_FILE"); path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+	if len(data) == 0 {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return cfg, errors.New("no JWT key configured: set JWT_KEYS, JWT_KEYS_FILE or JWT_SECRET")
+		}
+		log.Println("warning: JWT_KEYS not configured, falling back to a single HS256 key from JWT_SECRET")
+		return KeyRingConfig{
+			SigningKeyID: "default",
+			Keys:         []KeyConfig{{ID: "default", Algorithm: "HS256", Secret: secret}},
+		}, nil
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid JWT key configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// NewKeyRing builds a key ring from its configuration.
+func NewKeyRing(cfg KeyRingConfig) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]*jwtKey)}
+	for _, kc := range cfg.Keys {
+		if kc.ID == "" {
+			return nil, errors.New("every JWT key needs a kid")
+		}
+		if _, dup := ring.keys[kc.ID]; dup {
+			return nil, fmt.Errorf("duplicate JWT kid %q", kc.ID)
+		}
+		key, err := parseKeyConfig(kc)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", kc.ID, err)
+		}
+		ring.keys[kc.ID] = key
+	}
+
+	signing, ok := ring.keys[cfg.SigningKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing kid %q is not configured", cfg.SigningKeyID)
+	}
+	if signing.signKey == nil {
+		return nil, fmt.Errorf("signing kid %q has no private key", cfg.SigningKeyID)
+	}
+	ring.signing = signing
+	return ring, nil
+}
+
+func parseKeyConfig(kc KeyConfig) (*jwtKey, error) {
+	key := &jwtKey{id: kc.ID, notAfter: kc.NotAfter}
+
+	switch kc.Algorithm {
+	case "HS256":
+		if kc.Secret == "" {
+			return nil, errors.New("HS256 keys need a secret")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(kc.Secret)
+		key.verifyKey = []byte(kc.Secret)
+		return key, nil
+	case "RS256":
+		key.method = jwt.SigningMethodRS256
+	case "EdDSA":
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", kc.Algorithm)
+	}
+
+	privatePEM, err := readPEM(kc.PrivateKey, kc.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if privatePEM != nil {
+		private, err := parsePrivateKey(privatePEM)
+		if err != nil {
+			return nil, err
+		}
+		switch k := private.(type) {
+		case *rsa.PrivateKey:
+			key.signKey, key.verifyKey = k, &k.PublicKey
+		case ed25519.PrivateKey:
+			key.signKey, key.verifyKey = k, k.Public()
+		}
+	} else {
+		publicPEM, err := readPEM(kc.PublicKey, kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if publicPEM == nil {
+			return nil, errors.New("a private or public key is required")
+		}
+		if key.verifyKey, err = x509.ParsePKIXPublicKey(publicPEM.Bytes); err != nil {
+			return nil, err
+		}
+	}
+
+	// Make sure the key material matches the declared algorithm.
+	switch key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		if key.method != jwt.SigningMethodRS256 {
+			return nil, errors.New("RSA key configured for a non-RS256 algorithm")
+		}
+	case ed25519.PublicKey:
+		if key.method != jwt.SigningMethodEdDSA {
+			return nil, errors.New("Ed25519 key configured for a non-EdDSA algorithm")
+		}
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+	return key, nil
+}
+
+func readPEM(inline, path string) (*pem.Block, error) {
+	data := []byte(inline)
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (interface{}, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// Sign signs the claims with the active key and stamps its kid in the header.
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.signing.method, claims)
+	token.Header["kid"] = r.signing.id
+	return token.SignedString(r.signing.signKey)
+}
+
+// Keyfunc resolves the verification key for a token from its kid header,
+// refusing unknown kids, expired keys and algorithm mismatches.
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if key.notAfter != nil && time.Now().After(*key.notAfter) {
+		return nil, fmt.Errorf("signing key %q has been retired", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q for key %q", token.Method.Alg(), kid)
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS returns the public keys that are still accepted, for publishing at
+// /.well-known/jwks.json.
+func (r *KeyRing) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range r.keys {
+		if key.notAfter != nil && time.Now().After(*key.notAfter) {
+			continue
+		}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.id,
+				Use:       "sig",
+				Algorithm: key.method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.id,
+				Use:       "sig",
+				Algorithm: key.method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
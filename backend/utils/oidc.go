@@ -0,0 +1,402 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCProviderConfig describes one OpenID Connect identity provider. The
+// endpoints are discovered from the issuer unless they are set explicitly.
+// Providers are read from OIDC_PROVIDERS (inline JSON) or OIDC_PROVIDERS_FILE,
+// e.g.
+//
+//	[
+//	  {"name": "google", "issuer": "https://accounts.google.com",
+//	   "client_id": "...", "client_secret": "...",
+//	   "redirect_url": "https://impacta.example/auth/callback/google"}
+//	]
+type OIDCProviderConfig struct {
+	Name                  string   `json:"name"`
+	Issuer                string   `json:"issuer"`
+	ClientID              string   `json:"client_id"`
+	ClientSecret          string   `json:"client_secret,omitempty"`
+	RedirectURL           string   `json:"redirect_url"`
+	Scopes                []string `json:"scopes,omitempty"` // Default "openid email profile"
+	AuthorizationEndpoint string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint         string   `json:"token_endpoint,omitempty"`
+	JWKSURI               string   `json:"jwks_uri,omitempty"`
+}
+
+// OIDCIdentity is what Impacta takes from a verified ID token.
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCProvider runs the relying-party side of the authorization code flow
+// with PKCE against one identity provider.
+type OIDCProvider struct {
+	cfg    OIDCProviderConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	discovered    bool
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+// OIDCProviders holds the configured providers by name.
+var OIDCProviders map[string]*OIDCProvider
+
+var oidcOnce sync.Once
+
+// jwksRefreshInterval limits how often an unknown kid triggers a refetch.
+const jwksRefreshInterval = 30 * time.Second
+
+// InitOIDCProviders loads OIDCProviders from configuration. An invalid
+// configuration disables OIDC login rather than stopping the server.
+func InitOIDCProviders() {
+	oidcOnce.Do(func() {
+		if OIDCProviders != nil {
+			return
+		}
+		OIDCProviders = make(map[string]*OIDCProvider)
+		configs, err := loadOIDCConfig()
+		if err != nil {
+			log.Println("OIDC login disabled:", err)
+			return
+		}
+		for _, cfg := range configs {
+			provider, err := NewOIDCProvider(cfg)
+			if err != nil {
+				log.Println("OIDC login disabled:", err)
+				OIDCProviders = map[string]*OIDCProvider{}
+				return
+			}
+			OIDCProviders[cfg.Name] = provider
+		}
+	})
+}
+
+func loadOIDCConfig() ([]OIDCProviderConfig, error) {
+	var configs []OIDCProviderConfig
+	data := []byte(os.Getenv("OIDC_PROVIDERS"))
+	if path := os.Getenv("OIDC_PROVIDERS_FILE"); path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid OIDC provider configuration: %w", err)
+	}
+	return configs, nil
+}
+
+// OIDCProviderByName returns the provider configured under name.
+func OIDCProviderByName(name string) (*OIDCProvider, bool) {
+	InitOIDCProviders()
+	provider, ok := OIDCProviders[name]
+	return provider, ok
+}
+
+// OIDCProviderNames lists the configured providers.
+func OIDCProviderNames() []string {
+	InitOIDCProviders()
+	names := make([]string, 0, len(OIDCProviders))
+	for name := range OIDCProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewOIDCProvider validates a provider configuration.
+func NewOIDCProvider(cfg OIDCProviderConfig) (*OIDCProvider, error) {
+	if cfg.Name == "" || cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("OIDC provider %q needs a name, issuer, client_id and redirect_url", cfg.Name)
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the name the provider is configured under.
+func (p *OIDCProvider) Name() string {
+	return p.cfg.Name
+}
+
+// PKCEChallenge derives the S256 code challenge for a code verifier.
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthorizationURL returns the URL the browser is sent to in order to sign in.
+func (p *OIDCProvider) AuthorizationURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", PKCEChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.cfg.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.cfg.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the raw ID token.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", verifier)
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(req, &body)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK || body.IDToken == "" {
+		return "", fmt.Errorf("token endpoint returned %d: %s %s", status, body.Error, body.ErrorDescription)
+	}
+	return body.IDToken, nil
+}
+
+// oidcClaims are the ID token claims Impacta reads. email_verified is a
+// boolean in the spec but some providers send the string "true".
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string      `json:"nonce"`
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"`
+	Name          string      `json:"name"`
+}
+
+// VerifyIDToken checks the ID token's signature, issuer, audience, expiry and
+// nonce, and returns the identity it asserts.
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, raw, nonce string) (OIDCIdentity, error) {
+	if err := p.discover(ctx); err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	claims := &oidcClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return OIDCIdentity{}, errors.New("ID token nonce does not match")
+	}
+	if claims.Subject == "" {
+		return OIDCIdentity{}, errors.New("ID token has no subject")
+	}
+
+	verified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+	return OIDCIdentity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: verified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// discover fills in the endpoints from the issuer's discovery document.
+func (p *OIDCProvider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered || (p.cfg.AuthorizationEndpoint != "" && p.cfg.TokenEndpoint != "" && p.cfg.JWKSURI != "") {
+		p.discovered = true
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimRight(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return err
+	}
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	status, err := p.doJSON(req, &doc)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("OIDC discovery for %q returned %d", p.cfg.Name, status)
+	}
+	if doc.Issuer != p.cfg.Issuer {
+		return fmt.Errorf("OIDC discovery for %q returned issuer %q", p.cfg.Name, doc.Issuer)
+	}
+	if p.cfg.AuthorizationEndpoint == "" {
+		p.cfg.AuthorizationEndpoint = doc.AuthorizationEndpoint
+	}
+	if p.cfg.TokenEndpoint == "" {
+		p.cfg.TokenEndpoint = doc.TokenEndpoint
+	}
+	if p.cfg.JWKSURI == "" {
+		p.cfg.JWKSURI = doc.JWKSURI
+	}
+	p.discovered = true
+	return nil
+}
+
+// key returns the provider's public key for kid, refetching the key set when
+// the kid is unknown so the provider can rotate keys.
+func (p *OIDCProvider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+			Curve   string `json:"crv"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		} `json:"keys"`
+	}
+	status, err := p.doJSON(req, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned %d", status)
+	}
+
+	keys := make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.KeyType {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[k.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if k.Curve != "P-256" || errX != nil || errY != nil {
+				continue
+			}
+			keys[k.KeyID] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// doJSON sends the request and decodes a JSON response body into v.
+func (p *OIDCProvider) doJSON(req *http.Request, v interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return resp.StatusCode, fmt.Errorf("invalid response from %s: %w", req.URL.Host, err)
+	}
+	return resp.StatusCode, nil
+}
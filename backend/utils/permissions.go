@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Built-in roles. Admins can create further roles (e.g. support_agent or
+// finance) and grant them any of the permissions below.
+const (
+	RoleAdmin           = "admin"
+	RoleCampaignCreator = "campaign_creator"
+	RoleDonor           = "donor"
+)
+
+// Permissions checked by middlewares.Require and by controllers that let
+// moderators act on other users' records.
+const (
+	PermCampaignCreate     = "campaign:create"
+	PermCampaignModerate   = "campaign:moderate"
+	PermCampaignReview     = "campaign:review"
+	PermMediaCreate        = "media:create"
+	PermCommentModerate    = "comment:moderate"
+	PermDonationRead       = "donation:read"
+	PermDonationUpdate     = "donation:update"
+	PermPaymentCreate      = "payment:create"
+	PermPaymentRead        = "payment:read"
+	PermPaymentManage      = "payment:manage"
+	PermWithdrawalCreate   = "withdrawal:create"
+	PermWithdrawalRead     = "withdrawal:read"
+	PermWithdrawalApprove  = "withdrawal:approve"
+	PermWithdrawalDelete   = "withdrawal:delete"
+	PermNotificationManage = "notification:manage"
+	PermSupportManage      = "support:manage"
+	PermUserRead           = "user:read"
+	PermUserManage         = "user:manage"
+	PermRoleManage         = "role:manage"
+	PermAuditRead          = "audit:read"
+)
+
+// Permission describes an entry of the permission catalog.
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PermissionCatalog lists every permission that can be granted to a role.
+var PermissionCatalog = []Permission{
+	{PermCampaignCreate, "Create campaigns"},
+	{PermCampaignModerate, "Edit or delete any campaign and its media"},
+	{PermCampaignReview, "Approve or reject campaigns submitted for review"},
+	{PermMediaCreate, "Upload media files"},
+	{PermCommentModerate, "Edit or delete any comment"},
+	{PermDonationRead, "View donations across all campaigns"},
+	{PermDonationUpdate, "Update donations"},
+	{PermPaymentCreate, "Record payment transactions"},
+	{PermPaymentRead, "View every payment transaction"},
+	{PermPaymentManage, "Update and delete payment transactions"},
+	{PermWithdrawalCreate, "Request withdrawals"},
+	{PermWithdrawalRead, "View every withdrawal"},
+	{PermWithdrawalApprove, "Approve, process or reject withdrawals"},
+	{PermWithdrawalDelete, "Delete withdrawals"},
+	{PermNotificationManage, "View and manage any user's notifications"},
+	{PermSupportManage, "View and manage all support tickets"},
+	{PermUserRead, "List all users"},
+	{PermUserManage, "Unlock, suspend, ban and reactivate user accounts"},
+	{PermRoleManage, "Manage roles and assign them to users"},
+	{PermAuditRead, "View and verify the admin audit log"},
+}
+
+// IsPermission reports whether name is in the permission catalog.
+func IsPermission(name string) bool {
+	for _, p := range PermissionCatalog {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRolePermissions are the grants seeded for the built-in roles. They
+// are also used when the roles tables cannot be read. Admin always holds
+// every permission and is not listed.
+var DefaultRolePermissions = map[string][]string{
+	RoleCampaignCreator: {PermCampaignCreate, PermMediaCreate, PermWithdrawalCreate},
+	RoleDonor:           {},
+}
+
+const rolePermissionsTTL = 30 * time.Second
+
+var rolePermissionsCache struct {
+	sync.Mutex
+	grants   map[string]map[string]bool
+	loadedAt time.Time
+}
+
+// HasPermission reports whether the role has been granted the permission.
+// Grants are read from the rolepermissions table and cached briefly.
+func HasPermission(role, permission string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	return rolePermissions()[role][permission]
+}
+
+// InvalidateRolePermissions drops the cached grants after a role changes.
+func InvalidateRolePermissions() {
+	rolePermissionsCache.Lock()
+	defer rolePermissionsCache.Unlock()
+	rolePermissionsCache.grants = nil
+}
+
+func rolePermissions() map[string]map[string]bool {
+	rolePermissionsCache.Lock()
+	defer rolePermissionsCache.Unlock()
+
+	if rolePermissionsCache.grants != nil && time.Since(rolePermissionsCache.loadedAt) < rolePermissionsTTL {
+		return rolePermissionsCache.grants
+	}
+
+	grants, err := loadRolePermissions()
+	if err != nil {
+		log.Println("Failed to load role permissions, using defaults:", err)
+		grants = defaultGrants()
+	}
+	rolePermissionsCache.grants = grants
+	rolePermissionsCache.loadedAt = time.Now()
+	return grants
+}
+
+func loadRolePermissions() (map[string]map[string]bool, error) {
+	if DB == nil {
+		return defaultGrants(), nil
+	}
+	var rows []struct {
+		RoleName   string
+		Permission string
+	}
+	if err := DB.Table("rolepermissions").Select("role_name, permission").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	grants := make(map[string]map[string]bool)
+	for _, row := range rows {
+		if grants[row.RoleName] == nil {
+			grants[row.RoleName] = make(map[string]bool)
+		}
+		grants[row.RoleName][row.Permission] = true
+	}
+	return grants, nil
+}
+
+func defaultGrants() map[string]map[string]bool {
+	grants := make(map[string]map[string]bool)
+	for role, perms := range DefaultRolePermissions {
+		grants[role] = make(map[string]bool)
+		for _, perm := range perms {
+			grants[role][perm] = true
+		}
+	}
+	return grants
+}
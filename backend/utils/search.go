@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PrefixTSQuery turns free text typed by a user into a Postgres tsquery that
+// matches every word as a prefix, e.g. "clean wat" becomes "clean:* & wat:*".
+// Everything but letters and digits is dropped, so the result is always
+// valid tsquery syntax. It returns "" when no words are left.
+func PrefixTSQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, strings.ToLower(word)+":*")
+	}
+	return strings.Join(terms, " & ")
+}